@@ -7,6 +7,7 @@ import (
 	"github.com/DevanshBhavsar3/echo/api/internal/handler/v1"
 	"github.com/DevanshBhavsar3/echo/api/internal/routes"
 	"github.com/DevanshBhavsar3/echo/common/db"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,10 +20,12 @@ func main() {
 	database := db.New(ctx)
 	defer database.Close()
 
+	rclient := redisClient.NewRedisClient(ctx)
+
 	app := fiber.New()
 
 	// Create route handlers
-	handlers := handler.NewHandler(database)
+	handlers := handler.NewHandler(database, rclient)
 
 	// Setup routes
 	routes.SetupRoutes(app, handlers)
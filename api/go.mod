@@ -5,17 +5,23 @@ go 1.24.4
 require (
 	github.com/DevanshBhavsar3/echo/common/config v0.0.0-00010101000000-000000000000
 	github.com/DevanshBhavsar3/echo/common/db v0.0.0-00010101000000-000000000000
+	github.com/DevanshBhavsar3/echo/common/mail v0.0.0-00010101000000-000000000000
+	github.com/DevanshBhavsar3/echo/common/redisClient v0.0.0-00010101000000-000000000000
+	github.com/andybalholm/cascadia v1.3.3
 	github.com/go-playground/validator/v10 v10.27.0
 	github.com/gofiber/fiber/v2 v2.52.8
 	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.7.5
+	github.com/redis/go-redis/v9 v9.11.0
 	golang.org/x/oauth2 v0.31.0
 )
 
 require (
 	cloud.google.com/go/compute/metadata v0.3.0 // indirect
 	github.com/andybalholm/brotli v1.2.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/gabriel-vasile/mimetype v1.4.9 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
@@ -29,6 +35,7 @@ require (
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.63.0 // indirect
 	golang.org/x/crypto v0.39.0 // indirect
@@ -41,4 +48,6 @@ require (
 replace (
 	github.com/DevanshBhavsar3/echo/common/config => ../common/config
 	github.com/DevanshBhavsar3/echo/common/db => ../common/db
+	github.com/DevanshBhavsar3/echo/common/mail => ../common/mail
+	github.com/DevanshBhavsar3/echo/common/redisClient => ../common/redisClient
 )
@@ -17,12 +17,14 @@ import (
 )
 
 type AuthHandler struct {
-	userStorage store.UserStorage
+	userStorage         store.UserStorage
+	organizationStorage store.OrganizationStorage
 }
 
-func NewAuthHandler(userStorage store.UserStorage) *AuthHandler {
+func NewAuthHandler(userStorage store.UserStorage, organizationStorage store.OrganizationStorage) *AuthHandler {
 	return &AuthHandler{
 		userStorage,
+		organizationStorage,
 	}
 }
 
@@ -69,19 +71,11 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Create token
-	claims := jwt.MapClaims{
-		"sub": pkg.JWTPayload{
-			ID:      newUser.ID,
-			Name:    newUser.Name,
-			Email:   newUser.Email,
-			Image:   newUser.Image,
-			IsAdmin: false,
-		},
-		"exp": time.Now().Add(pkg.Exp).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"iss": pkg.Iss,
-		"aud": pkg.Iss,
+	claims, err := h.userClaims(c.Context(), newUser)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create token.",
+		})
 	}
 
 	token, err := pkg.GenerateJWT(claims)
@@ -138,19 +132,11 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Create token
-	claims := jwt.MapClaims{
-		"sub": pkg.JWTPayload{
-			ID:      user.ID,
-			Name:    user.Name,
-			Email:   user.Email,
-			Image:   user.Image,
-			IsAdmin: false,
-		},
-		"exp": time.Now().Add(pkg.Exp).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"iss": pkg.Iss,
-		"aud": pkg.Iss,
+	claims, err := h.userClaims(c.Context(), user)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create token.",
+		})
 	}
 
 	token, err := pkg.GenerateJWT(claims)
@@ -200,16 +186,9 @@ func (h *AuthHandler) AdminLogin(c *fiber.Ctx) error {
 	}
 
 	// Create token
-	claims := jwt.MapClaims{
-		"sub": pkg.JWTPayload{
-			IsAdmin: true,
-		},
-		"exp": time.Now().Add(pkg.Exp).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"iss": pkg.Iss,
-		"aud": pkg.Iss,
-	}
+	claims := pkg.NewClaims(pkg.JWTPayload{
+		IsAdmin: true,
+	})
 
 	token, err := pkg.GenerateJWT(claims)
 	if err != nil {
@@ -299,19 +278,9 @@ func (h *AuthHandler) OAuthCallback(c *fiber.Ctx) error {
 	}
 
 	// Create token
-	claims := jwt.MapClaims{
-		"sub": pkg.JWTPayload{
-			ID:      user.ID,
-			Name:    user.Name,
-			Email:   user.Email,
-			Image:   user.Image,
-			IsAdmin: false,
-		},
-		"exp": time.Now().Add(pkg.Exp).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"iss": pkg.Iss,
-		"aud": pkg.Iss,
+	claims, err := h.userClaims(c.Context(), user)
+	if err != nil {
+		return c.Status(http.StatusSeeOther).Redirect(config.Get("FRONTEND_URL") + "/login?error=internal_error")
 	}
 
 	jwtToken, err := pkg.GenerateJWT(claims)
@@ -356,3 +325,20 @@ func (h *AuthHandler) GetUser(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(response)
 }
+
+// userClaims builds the token claims of a user, scoped to their default organization.
+func (h *AuthHandler) userClaims(ctx context.Context, user *store.User) (jwt.MapClaims, error) {
+	organization, err := h.organizationStorage.GetDefaultOrganization(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg.NewClaims(pkg.JWTPayload{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Image:          user.Image,
+		IsAdmin:        false,
+		OrganizationID: organization.ID,
+	}), nil
+}
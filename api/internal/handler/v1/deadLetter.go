@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// deadLetterStreams maps the :stream param to a dead letter stream and the stream
+// its messages are replayed to when they don't record where they came from.
+var deadLetterStreams = map[string][2]string{
+	"websites": {redisClient.WebsiteDeadLetterStream, redisClient.WebsiteStream},
+	"ticks":    {redisClient.DatabaseDeadLetterStream, redisClient.DatabaseStream},
+}
+
+type DeadLetterHandler struct {
+	client redisClient.RedisClient
+}
+
+func NewDeadLetterHandler(client redisClient.RedisClient) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		client,
+	}
+}
+
+func (h *DeadLetterHandler) GetDeadLetters(c *fiber.Ctx) error {
+	streams, ok := deadLetterStreams[c.Params("stream")]
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid stream.",
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 500.",
+		})
+	}
+
+	msgs, err := h.client.DeadLetters(c.Context(), streams[0], int64(limit))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting dead letters.",
+		})
+	}
+
+	var response []types.DeadLetter = []types.DeadLetter{}
+
+	for _, msg := range msgs {
+		deadLetter := types.DeadLetter{
+			ID: msg.ID,
+		}
+		deadLetter.OriginalID, _ = msg.Values["id"].(string)
+		deadLetter.Stream, _ = msg.Values["stream"].(string)
+		deadLetter.Data, _ = msg.Values["data"].(string)
+		deadLetter.Reason, _ = msg.Values["reason"].(string)
+		deadLetter.FailedAt, _ = msg.Values["failedAt"].(string)
+
+		response = append(response, deadLetter)
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *DeadLetterHandler) ReplayDeadLetter(c *fiber.Ctx) error {
+	streams, ok := deadLetterStreams[c.Params("stream")]
+	if !ok {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid stream.",
+		})
+	}
+
+	err := h.client.Replay(c.Context(), streams[0], streams[1], c.Params("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Dead letter not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error replaying dead letter.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DNSHandler struct {
+	dnsStorage store.DNSStorage
+}
+
+func NewDNSHandler(dnsStorage store.DNSStorage) *DNSHandler {
+	return &DNSHandler{
+		dnsStorage,
+	}
+}
+
+// GetDNS returns the pins and change history of a website. It drifts while the
+// latest snapshot of any region differs from the pins.
+func (h *DNSHandler) GetDNS(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 100.",
+		})
+	}
+
+	pins, err := h.dnsStorage.GetPins(c.Context(), website.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting dns pins.",
+		})
+	}
+
+	snapshots, err := h.dnsStorage.GetSnapshots(c.Context(), website.ID, limit)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting dns history.",
+		})
+	}
+
+	response := types.DNSResponse{
+		Status:    types.DNSStatusOK,
+		Pins:      pins,
+		Snapshots: snapshots,
+	}
+
+	seen := map[string]bool{}
+	for _, s := range snapshots {
+		if seen[s.RegionID] {
+			continue
+		}
+		seen[s.RegionID] = true
+
+		if len(s.Drift) > 0 {
+			response.Status = types.DNSStatusDrift
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *DNSHandler) UpdatePins(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	var body types.DNSPinsBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	err := h.dnsStorage.UpdatePins(c.Context(), website.ID, store.NewDNSRecords(body.Pins))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Website not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating dns pins.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
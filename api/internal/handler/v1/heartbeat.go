@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HeartbeatHandler struct {
+	heartbeatStorage store.HeartbeatStorage
+	client           redisClient.RedisClient
+}
+
+func NewHeartbeatHandler(heartbeatStorage store.HeartbeatStorage, client redisClient.RedisClient) *HeartbeatHandler {
+	return &HeartbeatHandler{
+		heartbeatStorage,
+		client,
+	}
+}
+
+// Ping records a ping of a heartbeat monitor. The event is start, success (the
+// default) or fail, and a non zero exit_code turns a success into a fail. The run
+// duration is taken from the duration query in milliseconds, or from the start ping.
+func (h *HeartbeatHandler) Ping(c *fiber.Ctx) error {
+	event := c.Params("event", store.HeartbeatSuccess)
+	if event != store.HeartbeatStart && event != store.HeartbeatSuccess && event != store.HeartbeatFail {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid heartbeat event.",
+		})
+	}
+
+	var exitCode *int
+	if c.Query("exit_code") != "" {
+		code, err := strconv.Atoi(c.Query("exit_code"))
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid exit code.",
+			})
+		}
+
+		exitCode = &code
+		if code != 0 && event == store.HeartbeatSuccess {
+			event = store.HeartbeatFail
+		}
+	}
+
+	duration := int64(c.QueryInt("duration", -1))
+	if c.Query("duration") != "" && duration < 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid duration.",
+		})
+	}
+
+	heartbeat, err := h.heartbeatStorage.GetHeartbeatByToken(c.Context(), pkg.HashToken(c.Params("token")))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Heartbeat not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting heartbeat.",
+			})
+		}
+	}
+
+	now := time.Now()
+
+	startedAt, err := h.heartbeatStorage.RecordPing(c.Context(), heartbeat.WebsiteID, event, now)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error recording heartbeat.",
+		})
+	}
+
+	// A start ping only opens the run, and paused monitors only keep the last ping
+	if event == store.HeartbeatStart || heartbeat.Paused {
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	if duration < 0 {
+		duration = 0
+		if startedAt != nil {
+			duration = now.Sub(*startedAt).Milliseconds()
+		}
+	}
+
+	status := store.Up
+	if event == store.HeartbeatFail {
+		status = store.Down
+	}
+
+	ticks := heartbeat.Ticks(status, now, duration, store.HeartbeatResult{Event: event, ExitCode: exitCode})
+
+	for _, tick := range ticks {
+		data, err := json.Marshal(tick)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error encoding heartbeat.",
+			})
+		}
+
+		if err := h.client.XAdd(c.Context(), redisClient.DatabaseStream, data); err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error recording heartbeat.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RotateToken replaces the ingestion token of a heartbeat monitor. The token is
+// only returned once.
+func (h *HeartbeatHandler) RotateToken(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	token, tokenHash, err := pkg.GenerateToken()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create heartbeat token.",
+		})
+	}
+
+	err = h.heartbeatStorage.SetToken(c.Context(), website.ID, tokenHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Website is not a heartbeat monitor.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating heartbeat token.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"token": token,
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncidentHandler struct {
+	incidentStorage store.IncidentStorage
+}
+
+func NewIncidentHandler(incidentStorage store.IncidentStorage) *IncidentHandler {
+	return &IncidentHandler{
+		incidentStorage,
+	}
+}
+
+func (h *IncidentHandler) GetIncidents(c *fiber.Ctx) error {
+	websiteId := c.Locals("website").(*store.Website).ID
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 100.",
+		})
+	}
+
+	incidents, err := h.incidentStorage.GetIncidents(c.Context(), websiteId, limit)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting incidents.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(incidents)
+}
+
+func (h *IncidentHandler) GetIncidentById(c *fiber.Ctx) error {
+	websiteId := c.Locals("website").(*store.Website).ID
+
+	incidentId := c.Params("incidentId")
+	if err := uuid.Validate(incidentId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident id.",
+		})
+	}
+
+	incident, err := h.incidentStorage.GetIncidentById(c.Context(), incidentId, websiteId)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Incident not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting incident.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(incident)
+}
+
+func (h *IncidentHandler) AcknowledgeIncident(c *fiber.Ctx) error {
+	websiteId := c.Locals("website").(*store.Website).ID
+
+	user := c.Locals("user").(pkg.JWTPayload)
+	incidentId := c.Params("incidentId")
+
+	if err := uuid.Validate(incidentId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident id.",
+		})
+	}
+
+	incident, err := h.incidentStorage.AcknowledgeIncident(c.Context(), incidentId, websiteId, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrIncidentNotOpen):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Incident is not open.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error acknowledging incident.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(incident)
+}
+
+func (h *IncidentHandler) ResolveIncident(c *fiber.Ctx) error {
+	websiteId := c.Locals("website").(*store.Website).ID
+
+	user := c.Locals("user").(pkg.JWTPayload)
+	incidentId := c.Params("incidentId")
+
+	if err := uuid.Validate(incidentId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid incident id.",
+		})
+	}
+
+	incident, err := h.incidentStorage.ResolveIncident(c.Context(), incidentId, websiteId, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrIncidentNotOpen):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Incident is already resolved.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error resolving incident.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(incident)
+}
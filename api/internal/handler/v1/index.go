@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,10 +14,84 @@ type Handler struct {
 		GetWebsiteById(c *fiber.Ctx) error
 		GetAllWebsites(c *fiber.Ctx) error
 		DeleteWebsite(c *fiber.Ctx) error
+		GetDeletedWebsites(c *fiber.Ctx) error
+		RestoreWebsite(c *fiber.Ctx) error
 		UpdateWebsite(c *fiber.Ctx) error
+		PauseWebsite(c *fiber.Ctx) error
+		ResumeWebsite(c *fiber.Ctx) error
 		GetTicks(c *fiber.Ctx) error
+		GetRecentTicks(c *fiber.Ctx) error
+		GetFailures(c *fiber.Ctx) error
 		GetMetrics(c *fiber.Ctx) error
 		GetUptime(c *fiber.Ctx) error
+		WebsiteAccess(c *fiber.Ctx) error
+	}
+	DNS interface {
+		GetDNS(c *fiber.Ctx) error
+		UpdatePins(c *fiber.Ctx) error
+	}
+	SLO interface {
+		CreateSLO(c *fiber.Ctx) error
+		GetSLOs(c *fiber.Ctx) error
+		GetSLOStatus(c *fiber.Ctx) error
+		UpdateSLO(c *fiber.Ctx) error
+		DeleteSLO(c *fiber.Ctx) error
+	}
+	Maintenance interface {
+		CreateWindow(c *fiber.Ctx) error
+		GetWindows(c *fiber.Ctx) error
+		GetWindowById(c *fiber.Ctx) error
+		UpdateWindow(c *fiber.Ctx) error
+		DeleteWindow(c *fiber.Ctx) error
+	}
+	Heartbeat interface {
+		Ping(c *fiber.Ctx) error
+		RotateToken(c *fiber.Ctx) error
+	}
+	Incident interface {
+		GetIncidents(c *fiber.Ctx) error
+		GetIncidentById(c *fiber.Ctx) error
+		AcknowledgeIncident(c *fiber.Ctx) error
+		ResolveIncident(c *fiber.Ctx) error
+	}
+	Notification interface {
+		CreateChannel(c *fiber.Ctx) error
+		GetChannels(c *fiber.Ctx) error
+		GetChannelById(c *fiber.Ctx) error
+		UpdateChannel(c *fiber.Ctx) error
+		DeleteChannel(c *fiber.Ctx) error
+		GetDeliveries(c *fiber.Ctx) error
+		GetWebsiteChannels(c *fiber.Ctx) error
+		AttachChannel(c *fiber.Ctx) error
+		DetachChannel(c *fiber.Ctx) error
+	}
+	StatusPage interface {
+		CreateStatusPage(c *fiber.Ctx) error
+		GetStatusPages(c *fiber.Ctx) error
+		GetStatusPageById(c *fiber.Ctx) error
+		UpdateStatusPage(c *fiber.Ctx) error
+		DeleteStatusPage(c *fiber.Ctx) error
+		GetPublicStatusPage(c *fiber.Ctx) error
+	}
+	Organization interface {
+		CreateOrganization(c *fiber.Ctx) error
+		GetOrganizations(c *fiber.Ctx) error
+		SwitchOrganization(c *fiber.Ctx) error
+		Membership(c *fiber.Ctx) error
+		GetOrganization(c *fiber.Ctx) error
+		UpdateOrganization(c *fiber.Ctx) error
+		DeleteOrganization(c *fiber.Ctx) error
+		GetMembers(c *fiber.Ctx) error
+		UpdateMemberRole(c *fiber.Ctx) error
+		RemoveMember(c *fiber.Ctx) error
+		CreateInvite(c *fiber.Ctx) error
+		GetInvites(c *fiber.Ctx) error
+		DeleteInvite(c *fiber.Ctx) error
+		AcceptInvite(c *fiber.Ctx) error
+	}
+	DeadLetter interface {
+		GetDeadLetters(c *fiber.Ctx) error
+		ReplayDeadLetter(c *fiber.Ctx) error
 	}
 	Region interface {
 		GetRegions(c *fiber.Ctx) error
@@ -32,11 +107,20 @@ type Handler struct {
 	}
 }
 
-func NewHandler(db *pgxpool.Pool) Handler {
+func NewHandler(db *pgxpool.Pool, rclient redisClient.RedisClient) Handler {
 	store := store.NewStorage(db)
 
 	return Handler{
-		Website: NewWebsiteHandler(store.Website, store.Region, store.WebsiteTick),
-		Region:  NewRegionHandler(store.Region),
-		Auth:    NewAuthHandler(store.User)}
+		Website:      NewWebsiteHandler(store.Website, store.Region, store.WebsiteTick, store.Certificate),
+		DNS:          NewDNSHandler(store.DNS),
+		SLO:          NewSLOHandler(store.SLO),
+		Maintenance:  NewMaintenanceHandler(store.Maintenance),
+		Heartbeat:    NewHeartbeatHandler(store.Heartbeat, rclient),
+		Incident:     NewIncidentHandler(store.Incident),
+		Notification: NewNotificationHandler(store.Notification),
+		StatusPage:   NewStatusPageHandler(store.StatusPage, store.WebsiteTick, store.Incident),
+		Organization: NewOrganizationHandler(store.Organization),
+		DeadLetter:   NewDeadLetterHandler(rclient),
+		Region:       NewRegionHandler(store.Region),
+		Auth:         NewAuthHandler(store.User, store.Organization)}
 }
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type MaintenanceHandler struct {
+	maintenanceStorage store.MaintenanceStorage
+}
+
+func NewMaintenanceHandler(maintenanceStorage store.MaintenanceStorage) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceStorage,
+	}
+}
+
+func (h *MaintenanceHandler) CreateWindow(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	var body types.MaintenanceWindowBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	window, err := newMaintenanceWindow(body)
+	if err != nil {
+		return maintenanceWindowError(c, err)
+	}
+	window.OrganizationID = user.OrganizationID
+
+	id, err := h.maintenanceStorage.CreateWindow(c.Context(), *window)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrWebsiteNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid website provided.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error creating maintenance window.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id": id,
+	})
+}
+
+func (h *MaintenanceHandler) GetWindows(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	windows, err := h.maintenanceStorage.GetWindows(c.Context(), user.OrganizationID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting maintenance windows.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(windows)
+}
+
+// GetWindowById returns a window with whether it is active now and the start of
+// its next occurrence.
+func (h *MaintenanceHandler) GetWindowById(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	windowId := c.Params("id")
+
+	if err := uuid.Validate(windowId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid maintenance window id.",
+		})
+	}
+
+	window, err := h.maintenanceStorage.GetWindow(c.Context(), windowId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Maintenance window not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting maintenance window.",
+			})
+		}
+	}
+
+	now := time.Now()
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"window":    window,
+		"active":    window.Active(now),
+		"nextStart": window.Next(now),
+	})
+}
+
+func (h *MaintenanceHandler) UpdateWindow(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	windowId := c.Params("id")
+
+	if err := uuid.Validate(windowId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid maintenance window id.",
+		})
+	}
+
+	var body types.MaintenanceWindowBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	window, err := newMaintenanceWindow(body)
+	if err != nil {
+		return maintenanceWindowError(c, err)
+	}
+	window.ID = windowId
+	window.OrganizationID = user.OrganizationID
+
+	err = h.maintenanceStorage.UpdateWindow(c.Context(), *window)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Maintenance window not found.",
+			})
+		case errors.Is(err, store.ErrWebsiteNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid website provided.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating maintenance window.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *MaintenanceHandler) DeleteWindow(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	windowId := c.Params("id")
+
+	if err := uuid.Validate(windowId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid maintenance window id.",
+		})
+	}
+
+	err := h.maintenanceStorage.DeleteWindow(c.Context(), windowId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Maintenance window not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting maintenance window.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func newMaintenanceWindow(body types.MaintenanceWindowBody) (*store.MaintenanceWindow, error) {
+	window := &store.MaintenanceWindow{
+		Name:            body.Name,
+		StartsAt:        body.StartsAt,
+		DurationSeconds: body.DurationMinutes * 60,
+		Until:           body.Until,
+		Timezone:        "UTC",
+		SkipChecks:      body.SkipChecks,
+		AllWebsites:     body.AllWebsites,
+		WebsiteIDs:      body.WebsiteIDs,
+	}
+
+	if body.Timezone != "" {
+		window.Timezone = body.Timezone
+	}
+
+	if body.Recurrence != "" {
+		if _, err := store.ParseRecurrence(body.Recurrence); err != nil {
+			return nil, ErrInvalidRecurrence
+		}
+		window.Recurrence = &body.Recurrence
+	}
+
+	if body.Until != nil && body.Until.Before(body.StartsAt) {
+		return nil, ErrInvalidUntil
+	}
+
+	// Windows of the whole organization also cover websites added later
+	if window.AllWebsites {
+		window.WebsiteIDs = nil
+	} else if len(window.WebsiteIDs) == 0 {
+		return nil, ErrNoWebsites
+	}
+
+	return window, nil
+}
+
+func maintenanceWindowError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidRecurrence):
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid recurrence, expected a cron expression.",
+		})
+	case errors.Is(err, ErrInvalidUntil):
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Until must be after the start.",
+		})
+	default:
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Websites are required unless the window covers all websites.",
+		})
+	}
+}
+
+var (
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+	ErrInvalidUntil      = errors.New("until is before the start")
+	ErrNoWebsites        = errors.New("maintenance window without websites")
+)
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Longest wait for resolving the host of a channel url
+var DestinationLookupTimeout = 5 * time.Second
+
+type NotificationHandler struct {
+	notificationStorage store.NotificationStorage
+}
+
+func NewNotificationHandler(notificationStorage store.NotificationStorage) *NotificationHandler {
+	return &NotificationHandler{
+		notificationStorage,
+	}
+}
+
+func (h *NotificationHandler) CreateChannel(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	var body types.NotificationChannelBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	if body.Type != store.ChannelEmail {
+		if err := publicDestination(c.Context(), body.Url); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Url must point to a public address.",
+			})
+		}
+	}
+
+	channel, err := newChannel(body)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error encoding channel config.",
+		})
+	}
+
+	id, err := h.notificationStorage.CreateChannel(c.Context(), *channel, user.ID, user.OrganizationID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating notification channel.",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id": id,
+	})
+}
+
+func (h *NotificationHandler) GetChannels(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	channels, err := h.notificationStorage.GetChannels(c.Context(), user.OrganizationID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting notification channels.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(redactChannels(c, channels))
+}
+
+func (h *NotificationHandler) GetChannelById(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	channel, err := h.notificationStorage.GetChannelById(c.Context(), channelId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting notification channel.",
+			})
+		}
+	}
+
+	if !canEditChannels(c) {
+		*channel = channel.Redacted()
+	}
+
+	return c.Status(http.StatusOK).JSON(channel)
+}
+
+func (h *NotificationHandler) UpdateChannel(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	var body types.NotificationChannelBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	if body.Type != store.ChannelEmail {
+		if err := publicDestination(c.Context(), body.Url); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Url must point to a public address.",
+			})
+		}
+	}
+
+	channel, err := newChannel(body)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error encoding channel config.",
+		})
+	}
+	channel.ID = channelId
+
+	err = h.notificationStorage.UpdateChannel(c.Context(), *channel, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating notification channel.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) DeleteChannel(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	err := h.notificationStorage.DeleteChannel(c.Context(), channelId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting notification channel.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) GetDeliveries(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 100.",
+		})
+	}
+
+	_, err := h.notificationStorage.GetChannelById(c.Context(), channelId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting notification channel.",
+			})
+		}
+	}
+
+	deliveries, err := h.notificationStorage.GetDeliveries(c.Context(), channelId, limit)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting deliveries.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(deliveries)
+}
+
+func (h *NotificationHandler) GetWebsiteChannels(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	channels, err := h.notificationStorage.GetWebsiteChannels(c.Context(), website.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting notification channels.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(redactChannels(c, channels))
+}
+
+func (h *NotificationHandler) AttachChannel(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	website := c.Locals("website").(*store.Website)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	_, err := h.notificationStorage.GetChannelById(c.Context(), channelId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting notification channel.",
+			})
+		}
+	}
+
+	err = h.notificationStorage.AttachChannel(c.Context(), website.ID, channelId)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrChannelAlreadyAttached):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Notification channel already attached.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error attaching notification channel.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) DetachChannel(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+	channelId := c.Params("channelId")
+
+	if err := uuid.Validate(channelId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid channel id.",
+		})
+	}
+
+	err := h.notificationStorage.DetachChannel(c.Context(), website.ID, channelId)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Notification channel not attached.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error detaching notification channel.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// canEditChannels reports whether the member may see the secrets of channels.
+func canEditChannels(c *fiber.Ctx) bool {
+	organization := c.Locals("organization").(*store.Organization)
+	return store.RoleAtLeast(organization.Role, store.RoleEditor)
+}
+
+func redactChannels(c *fiber.Ctx, channels []store.NotificationChannel) []store.NotificationChannel {
+	if canEditChannels(c) {
+		return channels
+	}
+
+	for i, ch := range channels {
+		channels[i] = ch.Redacted()
+	}
+
+	return channels
+}
+
+// publicDestination checks that a channel url is http or https and that its host
+// only resolves to public addresses. The db-worker checks the address again when
+// it connects, as the records can change.
+func publicDestination(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return store.ErrPrivateDestination
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DestinationLookupTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !store.PublicAddress(addr) {
+			return store.ErrPrivateDestination
+		}
+	}
+
+	return nil
+}
+
+// newChannel builds the stored channel with the config for its type.
+func newChannel(body types.NotificationChannelBody) (*store.NotificationChannel, error) {
+	var cfg any
+
+	switch body.Type {
+	case store.ChannelWebhook:
+		cfg = store.WebhookConfig{
+			Url:     body.Url,
+			Headers: body.Headers,
+			Secret:  body.Secret,
+		}
+	case store.ChannelEmail:
+		cfg = store.EmailConfig{
+			To: body.To,
+		}
+	default:
+		cfg = store.ChatConfig{
+			Url: body.Url,
+		}
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store.NotificationChannel{
+		Name:   body.Name,
+		Type:   body.Type,
+		Config: encoded,
+	}, nil
+}
@@ -0,0 +1,484 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/config"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/mail"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	InviteExpiry = 7 * 24 * time.Hour
+	// Longest wait for the SMTP server when sending an invite
+	InviteMailTimeout = 10 * time.Second
+)
+
+type OrganizationHandler struct {
+	organizationStorage store.OrganizationStorage
+}
+
+func NewOrganizationHandler(organizationStorage store.OrganizationStorage) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationStorage,
+	}
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	var body types.OrganizationBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	id, err := h.organizationStorage.CreateOrganization(c.Context(), body.Name, user.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating organization.",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id": id,
+	})
+}
+
+func (h *OrganizationHandler) GetOrganizations(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	organizations, err := h.organizationStorage.GetOrganizations(c.Context(), user.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting organizations.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(organizations)
+}
+
+// SwitchOrganization issues a new token with another organization of the user active.
+func (h *OrganizationHandler) SwitchOrganization(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	organizationId := c.Params("orgId")
+
+	if err := uuid.Validate(organizationId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid organization id.",
+		})
+	}
+
+	_, err := h.organizationStorage.GetMembership(c.Context(), organizationId, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Organization not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting organization.",
+			})
+		}
+	}
+
+	user.OrganizationID = organizationId
+
+	token, err := pkg.GenerateJWT(pkg.NewClaims(user))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create token.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"token": token,
+	})
+}
+
+// Membership loads the active organization of the user with their role and stores
+// it in the "organization" local for the handlers after it.
+func (h *OrganizationHandler) Membership(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	if uuid.Validate(user.OrganizationID) != nil {
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "No active organization.",
+		})
+	}
+
+	organization, err := h.organizationStorage.GetMembership(c.Context(), user.OrganizationID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "You are not a member of this organization.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting organization.",
+			})
+		}
+	}
+
+	c.Locals("organization", organization)
+	return c.Next()
+}
+
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+
+	return c.Status(http.StatusOK).JSON(organization)
+}
+
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+
+	var body types.OrganizationBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	err := h.organizationStorage.UpdateOrganization(c.Context(), organization.ID, body.Name)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error updating organization.",
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+
+	err := h.organizationStorage.DeleteOrganization(c.Context(), organization.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrPersonalOrganization):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Personal organization can't be deleted.",
+			})
+		case errors.Is(err, store.ErrOrganizationNotEmpty):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Delete the websites of the organization first.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting organization.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) GetMembers(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+
+	members, err := h.organizationStorage.GetMembers(c.Context(), organization.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting members.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(members)
+}
+
+func (h *OrganizationHandler) UpdateMemberRole(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+	userId := c.Params("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user id.",
+		})
+	}
+
+	var body types.MemberRoleBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	// Only owners can hand out or take away ownership
+	if organization.Role != store.RoleOwner {
+		member, err := h.organizationStorage.GetMembership(c.Context(), organization.ID, userId)
+		if body.Role == store.RoleOwner || (err == nil && member.Role == store.RoleOwner) {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to do this.",
+			})
+		}
+	}
+
+	err := h.organizationStorage.UpdateMemberRole(c.Context(), organization.ID, userId, body.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Member not found.",
+			})
+		case errors.Is(err, store.ErrLastOwner):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Organization must keep an owner.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating member.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RemoveMember is allowed to admins, and to any member removing themselves.
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	organization := c.Locals("organization").(*store.Organization)
+	userId := c.Params("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user id.",
+		})
+	}
+
+	if userId != user.ID && !store.RoleAtLeast(organization.Role, store.RoleAdmin) {
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to do this.",
+		})
+	}
+
+	if organization.Personal && userId == user.ID {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can't leave your personal organization.",
+		})
+	}
+
+	if organization.Role != store.RoleOwner {
+		member, err := h.organizationStorage.GetMembership(c.Context(), organization.ID, userId)
+		if err == nil && member.Role == store.RoleOwner {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to do this.",
+			})
+		}
+	}
+
+	err := h.organizationStorage.RemoveMember(c.Context(), organization.ID, userId)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Member not found.",
+			})
+		case errors.Is(err, store.ErrLastOwner):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Organization must keep an owner.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error removing member.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// CreateInvite mails the invite to its email and returns the token once, only its
+// hash is stored. The invite is kept when the mail can't be sent, so the token can
+// be shared another way.
+func (h *OrganizationHandler) CreateInvite(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	organization := c.Locals("organization").(*store.Organization)
+
+	var body types.InviteBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	token, tokenHash, err := pkg.GenerateToken()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create invite token.",
+		})
+	}
+
+	invite := store.OrganizationInvite{
+		Email:     body.Email,
+		Role:      body.Role,
+		InvitedBy: &user.ID,
+		ExpiresAt: time.Now().Add(InviteExpiry),
+	}
+
+	id, err := h.organizationStorage.CreateInvite(c.Context(), organization.ID, invite, tokenHash)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating invite.",
+		})
+	}
+
+	emailed := true
+	if err := sendInvite(c.Context(), organization.Name, invite, token); err != nil {
+		log.Printf("Error mailing invite %s: %v", *id, err)
+		emailed = false
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id":        id,
+		"token":     token,
+		"expiresAt": invite.ExpiresAt,
+		"emailed":   emailed,
+	})
+}
+
+func sendInvite(ctx context.Context, organization string, invite store.OrganizationInvite, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, InviteMailTimeout)
+	defer cancel()
+
+	link := config.Get("FRONTEND_URL") + "/invites/accept?token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf(
+		"You have been invited to join %s on Echo as %s.\r\n\r\nAccept the invite at %s\r\n\r\nThe invite expires on %s.",
+		organization,
+		invite.Role,
+		link,
+		invite.ExpiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+	)
+
+	return mail.Send(ctx, []string{invite.Email}, "[Echo] Invite to "+organization, body)
+}
+
+func (h *OrganizationHandler) GetInvites(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+
+	invites, err := h.organizationStorage.GetInvites(c.Context(), organization.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting invites.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(invites)
+}
+
+func (h *OrganizationHandler) DeleteInvite(c *fiber.Ctx) error {
+	organization := c.Locals("organization").(*store.Organization)
+	inviteId := c.Params("inviteId")
+
+	if err := uuid.Validate(inviteId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid invite id.",
+		})
+	}
+
+	err := h.organizationStorage.DeleteInvite(c.Context(), organization.ID, inviteId)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Invite not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting invite.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// AcceptInvite joins the organization of the invite and returns a token with it active.
+func (h *OrganizationHandler) AcceptInvite(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	var body types.AcceptInviteBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	organizationId, err := h.organizationStorage.AcceptInvite(c.Context(), pkg.HashToken(body.Token), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInvite):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid or expired invite.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error accepting invite.",
+			})
+		}
+	}
+
+	user.OrganizationID = *organizationId
+
+	token, err := pkg.GenerateJWT(pkg.NewClaims(user))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Cannot create token.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"token": token,
+	})
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Rolling window of SLOs created without one
+var DefaultSLOWindowDays = 30
+
+type SLOHandler struct {
+	sloStorage store.SLOStorage
+}
+
+func NewSLOHandler(sloStorage store.SLOStorage) *SLOHandler {
+	return &SLOHandler{
+		sloStorage,
+	}
+}
+
+func (h *SLOHandler) CreateSLO(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	var body types.SLOBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	slo, err := newSLO(body)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Window must be at most %d days.", store.MaxSLOWindowDays),
+		})
+	}
+	slo.WebsiteID = website.ID
+
+	id, err := h.sloStorage.CreateSLO(c.Context(), *slo)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error creating slo.",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id": id,
+	})
+}
+
+func (h *SLOHandler) GetSLOs(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	slos, err := h.sloStorage.GetSLOs(c.Context(), website.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting slos.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(slos)
+}
+
+// GetSLOStatus returns an SLO with its error budget and burn rates as of now.
+func (h *SLOHandler) GetSLOStatus(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+	sloId := c.Params("sloId")
+
+	if err := uuid.Validate(sloId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid slo id.",
+		})
+	}
+
+	slo, err := h.sloStorage.GetSLO(c.Context(), sloId, website.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "SLO not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting slo.",
+			})
+		}
+	}
+
+	status, err := h.sloStorage.GetStatus(c.Context(), *slo, time.Now())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error computing slo status.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(status)
+}
+
+func (h *SLOHandler) UpdateSLO(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+	sloId := c.Params("sloId")
+
+	if err := uuid.Validate(sloId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid slo id.",
+		})
+	}
+
+	var body types.SLOBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	slo, err := newSLO(body)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Window must be at most %d days.", store.MaxSLOWindowDays),
+		})
+	}
+	slo.ID = sloId
+	slo.WebsiteID = website.ID
+
+	err = h.sloStorage.UpdateSLO(c.Context(), *slo)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "SLO not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating slo.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *SLOHandler) DeleteSLO(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+	sloId := c.Params("sloId")
+
+	if err := uuid.Validate(sloId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid slo id.",
+		})
+	}
+
+	err := h.sloStorage.DeleteSLO(c.Context(), sloId, website.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "SLO not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting slo.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// newSLO builds the SLO of a request body, with the defaults of omitted fields.
+func newSLO(body types.SLOBody) (*store.SLO, error) {
+	slo := &store.SLO{
+		Name:               body.Name,
+		Target:             body.Target,
+		Window:             store.SLORolling,
+		WindowDays:         DefaultSLOWindowDays,
+		LatencyThresholdMS: body.LatencyThresholdMS,
+	}
+
+	if body.Window != "" {
+		slo.Window = store.SLOWindow(body.Window)
+	}
+
+	if body.WindowDays != 0 {
+		slo.WindowDays = body.WindowDays
+	}
+
+	if slo.WindowDays > store.MaxSLOWindowDays {
+		return nil, ErrSLOWindowTooLong
+	}
+
+	return slo, nil
+}
+
+var (
+	ErrSLOWindowTooLong = errors.New("slo window is longer than the tick retention")
+)
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/api/internal/types"
+	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	StatusPageUptimeDays    = 90
+	StatusPageIncidentDays  = 14
+	StatusPageIncidentLimit = 20
+)
+
+type StatusPageHandler struct {
+	statusPageStorage store.StatusPageStorage
+	tickStorage       store.WebsiteTickStorage
+	incidentStorage   store.IncidentStorage
+}
+
+func NewStatusPageHandler(statusPageStorage store.StatusPageStorage, tickStorage store.WebsiteTickStorage, incidentStorage store.IncidentStorage) *StatusPageHandler {
+	return &StatusPageHandler{
+		statusPageStorage,
+		tickStorage,
+		incidentStorage,
+	}
+}
+
+func (h *StatusPageHandler) CreateStatusPage(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	var body types.StatusPageBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	id, err := h.statusPageStorage.CreateStatusPage(c.Context(), newStatusPage(body), user.ID, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrDuplicateSlug):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Slug already used.",
+			})
+		case errors.Is(err, store.ErrStatusPageWebsite):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid website provided.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error creating status page.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"id": id,
+	})
+}
+
+func (h *StatusPageHandler) GetStatusPages(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	pages, err := h.statusPageStorage.GetStatusPages(c.Context(), user.OrganizationID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting status pages.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(pages)
+}
+
+func (h *StatusPageHandler) GetStatusPageById(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	pageId := c.Params("id")
+
+	if err := uuid.Validate(pageId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status page id.",
+		})
+	}
+
+	page, err := h.statusPageStorage.GetStatusPageById(c.Context(), pageId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Status page not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting status page.",
+			})
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(page)
+}
+
+func (h *StatusPageHandler) UpdateStatusPage(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	pageId := c.Params("id")
+
+	if err := uuid.Validate(pageId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status page id.",
+		})
+	}
+
+	var body types.StatusPageBody
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse body.",
+		})
+	}
+
+	if err := pkg.Validate.Struct(body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid body.",
+		})
+	}
+
+	page := newStatusPage(body)
+	page.ID = pageId
+
+	err := h.statusPageStorage.UpdateStatusPage(c.Context(), page, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Status page not found.",
+			})
+		case errors.Is(err, store.ErrDuplicateSlug):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Slug already used.",
+			})
+		case errors.Is(err, store.ErrStatusPageWebsite):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid website provided.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error updating status page.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *StatusPageHandler) DeleteStatusPage(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	pageId := c.Params("id")
+
+	if err := uuid.Validate(pageId); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status page id.",
+		})
+	}
+
+	err := h.statusPageStorage.DeleteStatusPage(c.Context(), pageId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Status page not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error deleting status page.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetPublicStatusPage is served without authentication, so the response is built
+// from display names only and never contains website ids. The statuses, uptimes and
+// incidents of the page are read in one query each.
+func (h *StatusPageHandler) GetPublicStatusPage(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+
+	page, err := h.statusPageStorage.GetStatusPageBySlug(c.Context(), slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Status page not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting status page.",
+			})
+		}
+	}
+
+	response := types.PublicStatusPageResponse{
+		Title:       page.Title,
+		Description: page.Description,
+		Status:      store.Up.String(),
+		Websites:    []types.PublicStatusPageWebsite{},
+		Incidents:   []types.PublicIncident{},
+	}
+
+	names := map[string]string{}
+	var websiteIds []string
+
+	for _, w := range page.Websites {
+		names[w.WebsiteID] = w.DisplayName
+		websiteIds = append(websiteIds, w.WebsiteID)
+	}
+
+	// One query per kind for the whole page
+	statuses, err := h.tickStorage.GetCurrentStatuses(c.Context(), websiteIds)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting website status.",
+		})
+	}
+
+	uptimes, err := h.tickStorage.GetDailyUptimes(c.Context(), websiteIds, StatusPageUptimeDays)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting website uptime.",
+		})
+	}
+
+	for _, w := range page.Websites {
+		status := statuses[w.WebsiteID]
+
+		website := types.PublicStatusPageWebsite{
+			Name:   w.DisplayName,
+			Status: status,
+			Uptime: uptimes[w.WebsiteID],
+		}
+		if w.ShowUrl {
+			website.Url = w.Url
+		}
+
+		// Planned maintenance doesn't degrade the page
+		if status != store.Up.String() && status != store.Unknown.String() && status != store.Maintenance.String() {
+			response.Status = "degraded"
+		}
+
+		response.Websites = append(response.Websites, website)
+	}
+
+	if len(websiteIds) > 0 {
+		since := time.Now().AddDate(0, 0, -StatusPageIncidentDays)
+
+		incidents, err := h.incidentStorage.GetRecentIncidents(c.Context(), websiteIds, since, StatusPageIncidentLimit)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting incidents.",
+			})
+		}
+
+		for _, i := range incidents {
+			incident := types.PublicIncident{
+				Website:         names[i.WebsiteID],
+				Status:          i.Status,
+				AffectedRegions: i.AffectedRegions,
+				StartedAt:       i.StartedAt.Format(time.RFC3339),
+				DurationSeconds: i.DurationSeconds,
+			}
+			if i.ResolvedAt != nil {
+				incident.ResolvedAt = i.ResolvedAt.Format(time.RFC3339)
+			}
+
+			response.Incidents = append(response.Incidents, incident)
+		}
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func newStatusPage(body types.StatusPageBody) store.StatusPage {
+	page := store.StatusPage{
+		Slug:        body.Slug,
+		Title:       body.Title,
+		Description: body.Description,
+	}
+
+	for _, w := range body.Websites {
+		page.Websites = append(page.Websites, store.StatusPageWebsite{
+			WebsiteID:   w.WebsiteID,
+			DisplayName: w.DisplayName,
+			ShowUrl:     w.ShowUrl,
+		})
+	}
+
+	return page
+}
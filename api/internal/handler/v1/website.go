@@ -3,27 +3,37 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DevanshBhavsar3/echo/api/internal/types"
 	"github.com/DevanshBhavsar3/echo/api/pkg"
+	"github.com/DevanshBhavsar3/echo/common/config"
 	"github.com/DevanshBhavsar3/echo/common/db/store"
 
+	"github.com/andybalholm/cascadia"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// How long a deleted website can be restored before its history is purged
+var WebsiteRestoreWindow = config.GetDuration("WEBSITE_RESTORE_WINDOW", 30*24*time.Hour)
+
 type WebsiteHandler struct {
-	websiteStorage store.WebsiteStorage
-	regionStorage  store.RegionStorage
-	tickStorage    store.WebsiteTickStorage
+	websiteStorage     store.WebsiteStorage
+	regionStorage      store.RegionStorage
+	tickStorage        store.WebsiteTickStorage
+	certificateStorage store.CertificateStorage
 }
 
-func NewWebsiteHandler(websiteStorage store.WebsiteStorage, regionStorage store.RegionStorage, tickStorage store.WebsiteTickStorage) *WebsiteHandler {
+func NewWebsiteHandler(websiteStorage store.WebsiteStorage, regionStorage store.RegionStorage, tickStorage store.WebsiteTickStorage, certificateStorage store.CertificateStorage) *WebsiteHandler {
 	return &WebsiteHandler{
 		websiteStorage,
 		regionStorage,
 		tickStorage,
+		certificateStorage,
 	}
 }
 
@@ -51,9 +61,22 @@ func (h *WebsiteHandler) AddWebsite(c *fiber.Ctx) error {
 		})
 	}
 
+	checkType := checkTypeOf(body.Type)
+
+	check, err := parseCheckSpec(checkType, body.Url, body.Check)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid check.",
+		})
+	}
+
 	newWebsite := store.Website{
 		Url:       body.Url,
 		Frequency: freq,
+		CheckType: checkType,
+		Check:     check,
+
+		CertificateExpiryDays: certificateExpiryDays(body.CertificateExpiryDays),
 	}
 	for _, r := range body.Regions {
 		region, err := h.regionStorage.GetRegionByName(c.Context(), r)
@@ -73,22 +96,37 @@ func (h *WebsiteHandler) AddWebsite(c *fiber.Ctx) error {
 		newWebsite.Regions = append(newWebsite.Regions, *region)
 	}
 
-	id, err := h.websiteStorage.CreateWebsite(c.Context(), newWebsite, user.ID)
+	// Heartbeat monitors are pinged on a secret url, the token is only shown once
+	var heartbeatToken string
+	if checkType == store.CheckHeartbeat {
+		token, tokenHash, err := pkg.GenerateToken()
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Cannot create heartbeat token.",
+			})
+		}
+
+		heartbeatToken = token
+		newWebsite.HeartbeatTokenHash = &tokenHash
+	}
+
+	id, err := h.websiteStorage.CreateWebsite(c.Context(), newWebsite, user.ID, user.OrganizationID)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error creating website.",
 		})
 	}
 
-	return c.Status(http.StatusCreated).JSON(fiber.Map{
-		"id": id,
+	return c.Status(http.StatusCreated).JSON(types.AddWebsiteResponse{
+		Id:             *id,
+		HeartbeatToken: heartbeatToken,
 	})
 }
 
 func (h *WebsiteHandler) GetAllWebsites(c *fiber.Ctx) error {
 	user := c.Locals("user").(pkg.JWTPayload)
 
-	websites, err := h.websiteStorage.GetAllWebsites(c.Context(), user.ID)
+	websites, err := h.websiteStorage.GetAllWebsites(c.Context(), user.OrganizationID)
 	if err != nil && err != store.ErrNotFound {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error getting websites.",
@@ -109,10 +147,13 @@ func (h *WebsiteHandler) GetAllWebsites(c *fiber.Ctx) error {
 		website := types.WebsiteWithTicks{
 			ID:        w.ID,
 			Url:       w.Url,
+			Type:      w.CheckType,
 			Frequency: pkg.ShortDuration(w.Frequency),
 			CreatedAt: w.CreatedAt.Format(time.RFC3339),
 			Ticks:     ticks,
 			Regions:   w.Regions,
+			Paused:    w.Paused(),
+			ResumeAt:  w.ResumeAt,
 		}
 
 		response = append(response, website)
@@ -151,7 +192,7 @@ func (h *WebsiteHandler) GetWebsiteById(c *fiber.Ctx) error {
 		})
 	}
 
-	website, err := h.websiteStorage.GetWebsiteById(c.Context(), websiteId, user.ID)
+	website, err := h.websiteStorage.GetWebsiteById(c.Context(), websiteId, user.OrganizationID)
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
@@ -175,15 +216,42 @@ func (h *WebsiteHandler) GetWebsiteById(c *fiber.Ctx) error {
 	response := types.GetWebsiteByIdResponse{
 		ID:        website.ID,
 		Url:       website.Url,
+		Type:      website.CheckType,
 		Frequency: pkg.ShortDuration(website.Frequency),
 		Regions:   website.Regions,
 		CreatedAt: website.CreatedAt.Format(time.RFC3339),
 		Uptime:    uptime,
+		Check:     website.Check,
+		Paused:    website.Paused(),
+		PausedAt:  website.PausedAt,
+		ResumeAt:  website.ResumeAt,
+
+		CertificateExpiryDays: website.CertificateExpiryDays,
+	}
+
+	cert, err := h.certificateStorage.GetCertificate(c.Context(), websiteId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting website certificate.",
+		})
+	}
+
+	if cert != nil {
+		now := time.Now()
+
+		response.Certificate = &types.CertificateResponse{
+			WebsiteCertificate: *cert,
+			DaysUntilExpiry:    cert.DaysUntilExpiry(now),
+			State:              cert.State(website.CertificateExpiryDays, now),
+		}
 	}
 
 	return c.Status(http.StatusOK).JSON(response)
 }
 
+// DeleteWebsite hides a website and stops its checks. It can be restored within
+// WebsiteRestoreWindow, after which its history is purged, archived first when
+// export=true.
 func (h *WebsiteHandler) DeleteWebsite(c *fiber.Ctx) error {
 	user := c.Locals("user").(pkg.JWTPayload)
 	websiteId := c.Params("id")
@@ -195,7 +263,9 @@ func (h *WebsiteHandler) DeleteWebsite(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.websiteStorage.DeleteWebsite(c.Context(), websiteId, user.ID)
+	purgeAfter := time.Now().Add(WebsiteRestoreWindow)
+
+	err = h.websiteStorage.DeleteWebsite(c.Context(), websiteId, user.OrganizationID, purgeAfter, c.QueryBool("export"))
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
@@ -209,6 +279,110 @@ func (h *WebsiteHandler) DeleteWebsite(c *fiber.Ctx) error {
 		}
 	}
 
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"purgeAfter": purgeAfter.Format(time.RFC3339),
+	})
+}
+
+// GetDeletedWebsites returns the deleted websites that can still be restored.
+func (h *WebsiteHandler) GetDeletedWebsites(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+
+	websites, err := h.websiteStorage.GetDeletedWebsites(c.Context(), user.OrganizationID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting deleted websites.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(websites)
+}
+
+func (h *WebsiteHandler) RestoreWebsite(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	websiteId := c.Params("id")
+
+	err := uuid.Validate(websiteId)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid website id.",
+		})
+	}
+
+	err = h.websiteStorage.RestoreWebsite(c.Context(), websiteId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Deleted website not found or past its restore window.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error restoring website.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// PauseWebsite stops checking a website, optionally until a resume time. Ticks
+// aren't recorded while paused, so the paused period counts as no data.
+func (h *WebsiteHandler) PauseWebsite(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	website := c.Locals("website").(*store.Website)
+
+	var body types.PauseWebsiteBody
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Failed to parse body.",
+			})
+		}
+	}
+
+	if body.ResumeAt != nil && !body.ResumeAt.After(time.Now()) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Resume time must be in the future.",
+		})
+	}
+
+	err := h.websiteStorage.PauseWebsite(c.Context(), website.ID, user.OrganizationID, body.ResumeAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Website not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error pausing website.",
+			})
+		}
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h *WebsiteHandler) ResumeWebsite(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	website := c.Locals("website").(*store.Website)
+
+	err := h.websiteStorage.ResumeWebsite(c.Context(), website.ID, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Website not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error resuming website.",
+			})
+		}
+	}
+
 	return c.SendStatus(http.StatusNoContent)
 }
 
@@ -237,10 +411,23 @@ func (h *WebsiteHandler) UpdateWebsite(c *fiber.Ctx) error {
 		})
 	}
 
+	checkType := checkTypeOf(body.Type)
+
+	check, err := parseCheckSpec(checkType, body.Url, body.Check)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid check.",
+		})
+	}
+
 	updatedWebsite := store.Website{
 		ID:        websiteId,
 		Url:       body.Url,
 		Frequency: freq,
+		CheckType: checkType,
+		Check:     check,
+
+		CertificateExpiryDays: certificateExpiryDays(body.CertificateExpiryDays),
 	}
 	for _, r := range body.Regions {
 		region, err := h.regionStorage.GetRegionByName(c.Context(), r)
@@ -260,7 +447,7 @@ func (h *WebsiteHandler) UpdateWebsite(c *fiber.Ctx) error {
 		updatedWebsite.Regions = append(updatedWebsite.Regions, *region)
 	}
 
-	err = h.websiteStorage.UpdateWebsite(c.Context(), updatedWebsite, user.ID)
+	err = h.websiteStorage.UpdateWebsite(c.Context(), updatedWebsite, user.OrganizationID)
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
@@ -277,36 +464,107 @@ func (h *WebsiteHandler) UpdateWebsite(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// GetRecentTicks returns the latest ticks of a region with their check details.
+func (h *WebsiteHandler) GetRecentTicks(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	region := c.Query("region")
+	if region == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Please provide region.",
+		})
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 100.",
+		})
+	}
+
+	ticks, err := h.tickStorage.GetRecentTicks(c.Context(), website.ID, region, limit)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting ticks.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(ticks)
+}
+
+// Longest time window of a failure aggregation
+var MaxFailureWindow = 90 * 24 * time.Hour
+
+// GetFailures aggregates the failed ticks of a website by reason over the range of
+// the query, the last day by default. The region is optional.
+func (h *WebsiteHandler) GetFailures(c *fiber.Ctx) error {
+	website := c.Locals("website").(*store.Website)
+
+	r, err := parseRange(c, 24*time.Hour)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": rangeError(err),
+		})
+	}
+
+	if r.To.Sub(r.From) > MaxFailureWindow {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Time window must be at most 90 days.",
+		})
+	}
+
+	failures, err := h.tickStorage.GetFailures(c.Context(), website.ID, c.Query("region"), r.From, r.To)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting failures.",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(failures)
+}
+
+// GetTicks returns the tick series of the regions over the range of the query, the
+// last day by default, or the last days for older clients. The bucket is picked for
+// the range unless given. Several regions are returned by name, a single region as
+// a plain series.
 func (h *WebsiteHandler) GetTicks(c *fiber.Ctx) error {
-	websiteId := c.Params("id")
+	website := c.Locals("website").(*store.Website)
 
-	err := uuid.Validate(websiteId)
+	regions, err := parseRegions(c)
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid website id.",
+			"error": "Please provide up to 10 regions.",
 		})
 	}
 
-	days := c.Query("days")
-	if days == "" {
+	r, err := parseRange(c, 24*time.Hour)
+	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Please provide tick range.",
+			"error": rangeError(err),
 		})
 	}
 
-	region := c.Query("region")
-	if region == "" {
+	bucket, err := parseBucket(c.Query("bucket"))
+	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Please provide region.",
+			"error": "Invalid bucket.",
 		})
 	}
 
-	ticks, err := h.tickStorage.GetTicks(c.Context(), websiteId, days, region)
+	bucket, err = store.TickBucket(r, len(regions), bucket)
 	if err != nil {
 		switch {
-		case errors.Is(err, store.ErrNotFound):
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{
-				"error": "No ticks found for this website.",
+		case errors.Is(err, store.ErrInvalidBucket):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Bucket must be a whole number of minutes.",
+			})
+		case errors.Is(err, store.ErrTooManyPoints):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Too many points, use a larger bucket or a shorter range.",
+			})
+		case errors.Is(err, store.ErrBucketTooFine):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Bucket is too fine for ticks this old.",
 			})
 		default:
 			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
@@ -315,35 +573,75 @@ func (h *WebsiteHandler) GetTicks(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.Status(http.StatusOK).JSON(ticks)
+	ticks, err := h.tickStorage.GetTicks(c.Context(), website.ID, r, bucket, regions)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error getting ticks.",
+		})
+	}
+
+	if c.Query("regions") == "" {
+		return c.Status(http.StatusOK).JSON(ticks)
+	}
+
+	response := types.TicksResponse{
+		From:          r.From,
+		To:            r.To,
+		BucketSeconds: int64(bucket.Seconds()),
+		Regions:       map[string][]store.Tick{},
+	}
+	for _, region := range regions {
+		response.Regions[region] = []store.Tick{}
+	}
+	for _, tick := range ticks {
+		response.Regions[tick.Region.Name] = append(response.Regions[tick.Region.Name], tick)
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
 }
 
+// GetMetrics returns the metrics of the regions over the range of the query, the
+// last month by default, compared with the range of the same length before it.
+// Several regions are returned by name, a single region as plain metrics.
 func (h *WebsiteHandler) GetMetrics(c *fiber.Ctx) error {
-	websiteId := c.Params("id")
+	website := c.Locals("website").(*store.Website)
 
-	region := c.Query("region")
-
-	if region == "" {
+	regions, err := parseRegions(c)
+	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Please provide region.",
+			"error": "Please provide up to 10 regions.",
 		})
 	}
 
-	err := uuid.Validate(websiteId)
+	r, err := parseRange(c, 30*24*time.Hour)
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid website id.",
+			"error": rangeError(err),
 		})
 	}
 
-	metrics, err := h.tickStorage.GetMetrics(c.Context(), websiteId, region)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error getting metrics.",
-		})
+	response := types.MetricsResponse{
+		From:    r.From,
+		To:      r.To,
+		Regions: map[string]*store.Metrics{},
+	}
+
+	for _, region := range regions {
+		metrics, err := h.tickStorage.GetMetrics(c.Context(), website.ID, region, r)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting metrics.",
+			})
+		}
+
+		response.Regions[region] = metrics
 	}
 
-	return c.Status(http.StatusOK).JSON(metrics)
+	if c.Query("regions") == "" {
+		return c.Status(http.StatusOK).JSON(response.Regions[regions[0]])
+	}
+
+	return c.Status(http.StatusOK).JSON(response)
 }
 
 func (h *WebsiteHandler) GetUptime(c *fiber.Ctx) error {
@@ -379,3 +677,341 @@ func (h *WebsiteHandler) GetUptime(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(uptime[0])
 }
+
+// Most regions compared in one request
+var MaxQueryRegions = 10
+
+// parseRegions reads the comma separated regions of the query, or its single region.
+func parseRegions(c *fiber.Ctx) ([]string, error) {
+	var regions []string
+
+	for _, region := range strings.Split(c.Query("regions", c.Query("region")), ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+
+	if len(regions) == 0 || len(regions) > MaxQueryRegions {
+		return nil, ErrInvalidRegions
+	}
+
+	return regions, nil
+}
+
+// parseRange reads the from and to ISO 8601 timestamps of the query. To defaults to
+// now and from to span before it, or to the days before it for older clients.
+func parseRange(c *fiber.Ctx, span time.Duration) (store.Range, error) {
+	r := store.Range{To: time.Now()}
+
+	if c.Query("to") != "" {
+		to, err := time.Parse(time.RFC3339, c.Query("to"))
+		if err != nil {
+			return r, ErrInvalidDate
+		}
+		r.To = to
+	}
+
+	switch {
+	case c.Query("from") != "":
+		from, err := time.Parse(time.RFC3339, c.Query("from"))
+		if err != nil {
+			return r, ErrInvalidDate
+		}
+		r.From = from
+	case c.Query("days") != "":
+		days, err := strconv.Atoi(c.Query("days"))
+		if err != nil || days < 1 {
+			return r, ErrInvalidDate
+		}
+		r.From = r.To.AddDate(0, 0, -days)
+	default:
+		r.From = r.To.Add(-span)
+	}
+
+	if !r.From.Before(r.To) {
+		return r, ErrEmptyRange
+	}
+
+	return r, nil
+}
+
+func rangeError(err error) string {
+	if errors.Is(err, ErrEmptyRange) {
+		return "From must be before to."
+	}
+
+	return "Invalid date format."
+}
+
+// parseBucket reads a bucket size like 30m, 1h or 7d. An empty bucket is zero.
+func parseBucket(bucket string) (time.Duration, error) {
+	if bucket == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(bucket, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return 0, ErrInvalidBucket
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(bucket)
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidBucket
+	}
+
+	return d, nil
+}
+
+// WebsiteAccess loads the website in the :id param from the active organization and stores
+// it in the "website" local for the handlers after it.
+func (h *WebsiteHandler) WebsiteAccess(c *fiber.Ctx) error {
+	user := c.Locals("user").(pkg.JWTPayload)
+	websiteId := c.Params("id")
+
+	err := uuid.Validate(websiteId)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid website id.",
+		})
+	}
+
+	website, err := h.websiteStorage.GetWebsiteById(c.Context(), websiteId, user.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Website not found.",
+			})
+		default:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error getting website.",
+			})
+		}
+	}
+
+	c.Locals("website", website)
+	return c.Next()
+}
+
+// checkTargets validate the target of each check type.
+var checkTargets = map[store.CheckType]string{
+	store.CheckHTTP:      "url",
+	store.CheckTCP:       "hostname_port",
+	store.CheckDNS:       "hostname_rfc1123",
+	store.CheckTLS:       "hostname_port|hostname_rfc1123",
+	store.CheckContent:   "url",
+	store.CheckMultiStep: "url",
+	// The url of a heartbeat monitor only names it
+	store.CheckHeartbeat: "max=2048",
+}
+
+func certificateExpiryDays(days int) int {
+	if days == 0 {
+		return store.DefaultCertificateExpiryDays
+	}
+
+	return days
+}
+
+func checkTypeOf(t string) store.CheckType {
+	if t == "" {
+		return store.CheckHTTP
+	}
+
+	return store.CheckType(t)
+}
+
+func parseCheckSpec(checkType store.CheckType, target string, body types.CheckSpecBody) (store.CheckSpec, error) {
+	if err := pkg.Validate.Var(target, checkTargets[checkType]); err != nil {
+		return store.CheckSpec{}, ErrInvalidTarget
+	}
+
+	check := store.CheckSpec{
+		TimeoutMS: body.TimeoutMS,
+	}
+
+	switch checkType {
+	case store.CheckDNS:
+		check.DNS = &store.DNSSpec{RecordType: "A"}
+		if body.DNS != nil {
+			check.DNS = &store.DNSSpec{
+				RecordType: body.DNS.RecordType,
+				Expected:   body.DNS.Expected,
+				Resolver:   body.DNS.Resolver,
+			}
+		}
+	case store.CheckTLS:
+		if body.TLS != nil {
+			check.TLS = &store.TLSSpec{ServerName: body.TLS.ServerName}
+		}
+	case store.CheckContent:
+		check.Content = &store.ContentSpec{}
+		if body.Content != nil {
+			check.Content = &store.ContentSpec{
+				Selector:  body.Content.Selector,
+				Regex:     body.Content.Regex,
+				Required:  body.Content.Required,
+				Forbidden: body.Content.Forbidden,
+			}
+		}
+
+		if check.Content.Selector != "" {
+			if _, err := cascadia.Compile(check.Content.Selector); err != nil {
+				return store.CheckSpec{}, err
+			}
+		}
+
+		if check.Content.Regex != "" {
+			if _, err := regexp.Compile(check.Content.Regex); err != nil {
+				return store.CheckSpec{}, err
+			}
+		}
+	case store.CheckHeartbeat:
+		if body.Heartbeat != nil {
+			check.Heartbeat = &store.HeartbeatSpec{GraceSeconds: body.Heartbeat.GraceSeconds}
+		}
+	case store.CheckMultiStep:
+		if len(body.Steps) == 0 {
+			return store.CheckSpec{}, ErrNoSteps
+		}
+
+		for _, b := range body.Steps {
+			step, err := parseStep(b)
+			if err != nil {
+				return store.CheckSpec{}, err
+			}
+
+			check.Steps = append(check.Steps, step)
+		}
+	}
+
+	if (body.DNS != nil && checkType != store.CheckDNS) ||
+		(body.TLS != nil && checkType != store.CheckTLS) ||
+		(body.Content != nil && checkType != store.CheckContent) ||
+		(len(body.Steps) > 0 && checkType != store.CheckMultiStep) ||
+		(body.Heartbeat != nil && checkType != store.CheckHeartbeat) {
+		return store.CheckSpec{}, ErrFieldForOtherCheckType
+	}
+
+	isHTTP := checkType == store.CheckHTTP || checkType == store.CheckContent
+
+	if checkType != store.CheckHTTP && (body.Method != "" || body.Body != "" || len(body.Assertions) > 0) {
+		return store.CheckSpec{}, ErrFieldForOtherCheckType
+	}
+
+	if !isHTTP && (len(body.Headers) > 0 || len(body.AcceptedStatus) > 0) {
+		return store.CheckSpec{}, ErrFieldForOtherCheckType
+	}
+
+	if !isHTTP {
+		return check, nil
+	}
+
+	check.Method = body.Method
+	check.Headers = body.Headers
+	check.Body = body.Body
+	check.AcceptedStatus = parseStatusRanges(body.AcceptedStatus)
+
+	assertions, err := parseAssertions(body.Assertions)
+	if err != nil {
+		return store.CheckSpec{}, err
+	}
+	check.Assertions = assertions
+
+	if len(check.Assertions) > 0 && check.HttpMethod() == http.MethodHead {
+		return store.CheckSpec{}, ErrAssertionWithoutBody
+	}
+
+	return check, nil
+}
+
+// parseStep validates a step of a multistep check. Its url is absolute or a path
+// relative to the url of the website.
+func parseStep(body types.StepBody) (store.Step, error) {
+	if !strings.HasPrefix(body.Url, "/") && pkg.Validate.Var(body.Url, "url") != nil {
+		return store.Step{}, ErrInvalidTarget
+	}
+
+	step := store.Step{
+		Name:           body.Name,
+		Method:         body.Method,
+		Url:            body.Url,
+		Headers:        body.Headers,
+		Body:           body.Body,
+		AcceptedStatus: parseStatusRanges(body.AcceptedStatus),
+	}
+
+	assertions, err := parseAssertions(body.Assertions)
+	if err != nil {
+		return store.Step{}, err
+	}
+	step.Assertions = assertions
+
+	for _, e := range body.Extract {
+		if e.From == string(store.ExtractRegex) {
+			if _, err := regexp.Compile(e.Path); err != nil {
+				return store.Step{}, err
+			}
+		}
+
+		step.Extract = append(step.Extract, store.Extraction{
+			Name: e.Name,
+			From: store.ExtractionSource(e.From),
+			Path: e.Path,
+		})
+	}
+
+	spec := step.Spec(0)
+	if (len(step.Assertions) > 0 || len(step.Extract) > 0) && spec.HttpMethod() == http.MethodHead {
+		return store.Step{}, ErrAssertionWithoutBody
+	}
+
+	return step, nil
+}
+
+func parseStatusRanges(body []types.StatusRangeBody) []store.StatusRange {
+	var ranges []store.StatusRange
+
+	for _, r := range body {
+		ranges = append(ranges, store.StatusRange{
+			Min: r.Min,
+			Max: r.Max,
+		})
+	}
+
+	return ranges
+}
+
+func parseAssertions(body []types.AssertionBody) ([]store.Assertion, error) {
+	var assertions []store.Assertion
+
+	for _, a := range body {
+		if a.Type == string(store.AssertRegex) {
+			if _, err := regexp.Compile(a.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		assertions = append(assertions, store.Assertion{
+			Type:  store.AssertionType(a.Type),
+			Path:  a.Path,
+			Value: a.Value,
+		})
+	}
+
+	return assertions, nil
+}
+
+var (
+	ErrAssertionWithoutBody   = errors.New("assertions need a method with a response body")
+	ErrInvalidTarget          = errors.New("invalid target for the check type")
+	ErrFieldForOtherCheckType = errors.New("check has options of another check type")
+	ErrNoSteps                = errors.New("multistep checks need at least one step")
+	ErrInvalidRegions         = errors.New("invalid regions")
+	ErrInvalidDate            = errors.New("invalid date")
+	ErrEmptyRange             = errors.New("from must be before to")
+	ErrInvalidBucket          = errors.New("invalid bucket")
+)
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole rejects members of the active organization below role. It must run
+// after the organization membership has been loaded.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		organization := c.Locals("organization").(*store.Organization)
+
+		if store.RoleAtLeast(organization.Role, role) {
+			return c.Next()
+		}
+
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to do this.",
+		})
+	}
+}
@@ -3,16 +3,26 @@ package routes
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/DevanshBhavsar3/echo/api/internal/handler/v1"
 	"github.com/DevanshBhavsar3/echo/api/internal/middleware"
 	"github.com/DevanshBhavsar3/echo/common/config"
+	"github.com/DevanshBhavsar3/echo/common/db/store"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cache"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
+var (
+	StatusPageCacheTTL = config.GetDuration("STATUS_PAGE_CACHE_TTL", 30*time.Second)
+	// Requests per minute and ip to public status pages missing the cache
+	StatusPageRateLimit = config.GetInt("STATUS_PAGE_RATE_LIMIT", 60)
+)
+
 func SetupRoutes(app *fiber.App, handlers handler.Handler) {
 	corsConfig := cors.Config{
 		AllowOrigins: fmt.Sprintf("%s,%s", config.Get("FRONTEND_URL"), config.Get("DOCKER_FRONTEND_URL")),
@@ -41,16 +51,98 @@ func SetupRoutes(app *fiber.App, handlers handler.Handler) {
 	oauthRouter.Get("/:provider", handlers.Auth.OAuthLogin)
 	oauthRouter.Get("/:provider/callback", handlers.Auth.OAuthCallback)
 
+	// Organization routes
+	organizationRouter := v1Router.Group("/organization", middleware.AuthMiddleware)
+	organizationRouter.Post("/", handlers.Organization.CreateOrganization)
+	organizationRouter.Get("/", handlers.Organization.GetOrganizations)
+	organizationRouter.Post("/invites/accept", handlers.Organization.AcceptInvite)
+	organizationRouter.Post("/:orgId/switch", handlers.Organization.SwitchOrganization)
+
+	currentOrganizationRouter := organizationRouter.Group("/current", handlers.Organization.Membership)
+	currentOrganizationRouter.Get("/", handlers.Organization.GetOrganization)
+	currentOrganizationRouter.Put("/", middleware.RequireRole(store.RoleAdmin), handlers.Organization.UpdateOrganization)
+	currentOrganizationRouter.Delete("/", middleware.RequireRole(store.RoleOwner), handlers.Organization.DeleteOrganization)
+	currentOrganizationRouter.Get("/members", handlers.Organization.GetMembers)
+	currentOrganizationRouter.Put("/members/:userId", middleware.RequireRole(store.RoleAdmin), handlers.Organization.UpdateMemberRole)
+	currentOrganizationRouter.Delete("/members/:userId", handlers.Organization.RemoveMember)
+	currentOrganizationRouter.Post("/invites", middleware.RequireRole(store.RoleAdmin), handlers.Organization.CreateInvite)
+	currentOrganizationRouter.Get("/invites", middleware.RequireRole(store.RoleAdmin), handlers.Organization.GetInvites)
+	currentOrganizationRouter.Delete("/invites/:inviteId", middleware.RequireRole(store.RoleAdmin), handlers.Organization.DeleteInvite)
+
 	// Website routes
-	websiteRouter := v1Router.Group("/website", middleware.AuthMiddleware)
-	websiteRouter.Post("/", handlers.Website.AddWebsite)
+	editor := middleware.RequireRole(store.RoleEditor)
+
+	websiteRouter := v1Router.Group("/website", middleware.AuthMiddleware, handlers.Organization.Membership)
+	websiteRouter.Post("/", editor, handlers.Website.AddWebsite)
 	websiteRouter.Get("/", handlers.Website.GetAllWebsites)
-	websiteRouter.Get("/ticks/:id", handlers.Website.GetTicks)
-	websiteRouter.Get("/metrics/:id", handlers.Website.GetMetrics)
-	websiteRouter.Get("/uptime/:id", handlers.Website.GetUptime)
-	websiteRouter.Put("/:id", handlers.Website.UpdateWebsite)
+	websiteRouter.Get("/deleted", handlers.Website.GetDeletedWebsites)
+	websiteRouter.Get("/ticks/:id", handlers.Website.WebsiteAccess, handlers.Website.GetTicks)
+	websiteRouter.Get("/ticks/:id/recent", handlers.Website.WebsiteAccess, handlers.Website.GetRecentTicks)
+	websiteRouter.Get("/failures/:id", handlers.Website.WebsiteAccess, handlers.Website.GetFailures)
+	websiteRouter.Get("/metrics/:id", handlers.Website.WebsiteAccess, handlers.Website.GetMetrics)
+	websiteRouter.Get("/uptime/:id", handlers.Website.WebsiteAccess, handlers.Website.GetUptime)
+	websiteRouter.Get("/:id/incidents", handlers.Website.WebsiteAccess, handlers.Incident.GetIncidents)
+	websiteRouter.Get("/:id/incidents/:incidentId", handlers.Website.WebsiteAccess, handlers.Incident.GetIncidentById)
+	websiteRouter.Post("/:id/incidents/:incidentId/acknowledge", editor, handlers.Website.WebsiteAccess, handlers.Incident.AcknowledgeIncident)
+	websiteRouter.Post("/:id/incidents/:incidentId/resolve", editor, handlers.Website.WebsiteAccess, handlers.Incident.ResolveIncident)
+	websiteRouter.Get("/:id/dns", handlers.Website.WebsiteAccess, handlers.DNS.GetDNS)
+	websiteRouter.Put("/:id/dns/pins", editor, handlers.Website.WebsiteAccess, handlers.DNS.UpdatePins)
+	websiteRouter.Post("/:id/slo", editor, handlers.Website.WebsiteAccess, handlers.SLO.CreateSLO)
+	websiteRouter.Get("/:id/slo", handlers.Website.WebsiteAccess, handlers.SLO.GetSLOs)
+	websiteRouter.Get("/:id/slo/:sloId", handlers.Website.WebsiteAccess, handlers.SLO.GetSLOStatus)
+	websiteRouter.Put("/:id/slo/:sloId", editor, handlers.Website.WebsiteAccess, handlers.SLO.UpdateSLO)
+	websiteRouter.Delete("/:id/slo/:sloId", editor, handlers.Website.WebsiteAccess, handlers.SLO.DeleteSLO)
+	websiteRouter.Post("/:id/pause", editor, handlers.Website.WebsiteAccess, handlers.Website.PauseWebsite)
+	websiteRouter.Post("/:id/resume", editor, handlers.Website.WebsiteAccess, handlers.Website.ResumeWebsite)
+	websiteRouter.Post("/:id/heartbeat/token", editor, handlers.Website.WebsiteAccess, handlers.Heartbeat.RotateToken)
+	websiteRouter.Get("/:id/notification", handlers.Website.WebsiteAccess, handlers.Notification.GetWebsiteChannels)
+	websiteRouter.Post("/:id/notification/:channelId", editor, handlers.Website.WebsiteAccess, handlers.Notification.AttachChannel)
+	websiteRouter.Delete("/:id/notification/:channelId", editor, handlers.Website.WebsiteAccess, handlers.Notification.DetachChannel)
+	websiteRouter.Put("/:id", editor, handlers.Website.UpdateWebsite)
 	websiteRouter.Get("/:id", handlers.Website.GetWebsiteById)
-	websiteRouter.Delete("/:id", handlers.Website.DeleteWebsite)
+	websiteRouter.Delete("/:id", editor, handlers.Website.DeleteWebsite)
+	websiteRouter.Post("/:id/restore", editor, handlers.Website.RestoreWebsite)
+
+	// Heartbeat routes, authenticated by the token in the url
+	heartbeatRouter := v1Router.Group("/heartbeat")
+	heartbeatRouter.Get("/:token/:event?", handlers.Heartbeat.Ping)
+	heartbeatRouter.Post("/:token/:event?", handlers.Heartbeat.Ping)
+
+	// Notification routes
+	notificationRouter := v1Router.Group("/notification", middleware.AuthMiddleware, handlers.Organization.Membership)
+	notificationRouter.Post("/", editor, handlers.Notification.CreateChannel)
+	notificationRouter.Get("/", handlers.Notification.GetChannels)
+	notificationRouter.Get("/:channelId/deliveries", handlers.Notification.GetDeliveries)
+	notificationRouter.Get("/:channelId", handlers.Notification.GetChannelById)
+	notificationRouter.Put("/:channelId", editor, handlers.Notification.UpdateChannel)
+	notificationRouter.Delete("/:channelId", editor, handlers.Notification.DeleteChannel)
+
+	// Maintenance window routes
+	maintenanceRouter := v1Router.Group("/maintenance", middleware.AuthMiddleware, handlers.Organization.Membership)
+	maintenanceRouter.Post("/", editor, handlers.Maintenance.CreateWindow)
+	maintenanceRouter.Get("/", handlers.Maintenance.GetWindows)
+	maintenanceRouter.Get("/:id", handlers.Maintenance.GetWindowById)
+	maintenanceRouter.Put("/:id", editor, handlers.Maintenance.UpdateWindow)
+	maintenanceRouter.Delete("/:id", editor, handlers.Maintenance.DeleteWindow)
+
+	// Status page routes
+	statusPageRouter := v1Router.Group("/status-page")
+	// Public pages are cached, and the requests missing the cache are limited per ip
+	statusPageRouter.Get("/public/:slug",
+		cache.New(cache.Config{Expiration: StatusPageCacheTTL}),
+		limiter.New(limiter.Config{Max: StatusPageRateLimit, Expiration: time.Minute}),
+		handlers.StatusPage.GetPublicStatusPage,
+	)
+	statusPageRouter.Post("/", middleware.AuthMiddleware, handlers.Organization.Membership, editor, handlers.StatusPage.CreateStatusPage)
+	statusPageRouter.Get("/", middleware.AuthMiddleware, handlers.Organization.Membership, handlers.StatusPage.GetStatusPages)
+	statusPageRouter.Get("/:id", middleware.AuthMiddleware, handlers.Organization.Membership, handlers.StatusPage.GetStatusPageById)
+	statusPageRouter.Put("/:id", middleware.AuthMiddleware, handlers.Organization.Membership, editor, handlers.StatusPage.UpdateStatusPage)
+	statusPageRouter.Delete("/:id", middleware.AuthMiddleware, handlers.Organization.Membership, editor, handlers.StatusPage.DeleteStatusPage)
+
+	// Dead letter routes
+	deadLetterRouter := v1Router.Group("/admin/dead-letter", middleware.AuthMiddleware, middleware.AdminMiddleware)
+	deadLetterRouter.Get("/:stream", handlers.DeadLetter.GetDeadLetters)
+	deadLetterRouter.Post("/:stream/:id/replay", handlers.DeadLetter.ReplayDeadLetter)
 
 	// Region routes
 	regionRouter := v1Router.Group("/region")
@@ -0,0 +1,10 @@
+package types
+
+type DeadLetter struct {
+	ID         string `json:"id"`
+	OriginalID string `json:"originalId"`
+	Stream     string `json:"stream"`
+	Data       string `json:"data"`
+	Reason     string `json:"reason"`
+	FailedAt   string `json:"failedAt"`
+}
@@ -0,0 +1,18 @@
+package types
+
+import "github.com/DevanshBhavsar3/echo/common/db/store"
+
+const (
+	DNSStatusOK    = "ok"
+	DNSStatusDrift = "drift"
+)
+
+type DNSPinsBody struct {
+	Pins map[string][]string `json:"pins" validate:"max=4,dive,keys,oneof=A AAAA CNAME NS,endkeys,min=1,max=20,dive,required,max=255"`
+}
+
+type DNSResponse struct {
+	Status    string              `json:"status"`
+	Pins      store.DNSRecords    `json:"pins"`
+	Snapshots []store.DNSSnapshot `json:"snapshots"`
+}
@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// A window recurs when Recurrence holds a cron expression like "0 2 * * SUN",
+// evaluated in Timezone. Without one it is a one-off window at StartsAt.
+type MaintenanceWindowBody struct {
+	Name            string     `json:"name" validate:"min=1,max=100"`
+	StartsAt        time.Time  `json:"startsAt" validate:"required"`
+	DurationMinutes int64      `json:"durationMinutes" validate:"min=1,max=10080"`
+	Recurrence      string     `json:"recurrence" validate:"max=100"`
+	Until           *time.Time `json:"until"`
+	Timezone        string     `json:"timezone" validate:"omitempty,timezone"`
+	SkipChecks      bool       `json:"skipChecks"`
+	AllWebsites     bool       `json:"allWebsites"`
+	WebsiteIDs      []string   `json:"websiteIds" validate:"max=100,unique,dive,uuid"`
+}
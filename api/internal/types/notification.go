@@ -0,0 +1,10 @@
+package types
+
+type NotificationChannelBody struct {
+	Name    string            `json:"name" validate:"min=1,max=100"`
+	Type    string            `json:"type" validate:"oneof=webhook email slack discord"`
+	Url     string            `json:"url" validate:"required_unless=Type email,omitempty,url"`
+	Headers map[string]string `json:"headers" validate:"max=20"`
+	Secret  string            `json:"secret" validate:"max=255"`
+	To      []string          `json:"to" validate:"required_if=Type email,max=10,dive,email"`
+}
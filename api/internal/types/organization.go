@@ -0,0 +1,18 @@
+package types
+
+type OrganizationBody struct {
+	Name string `json:"name" validate:"min=1,max=100"`
+}
+
+type InviteBody struct {
+	Email string `json:"email" validate:"email,max=255"`
+	Role  string `json:"role" validate:"oneof=admin editor viewer"`
+}
+
+type AcceptInviteBody struct {
+	Token string `json:"token" validate:"required,max=255"`
+}
+
+type MemberRoleBody struct {
+	Role string `json:"role" validate:"oneof=owner admin editor viewer"`
+}
@@ -0,0 +1,10 @@
+package types
+
+type SLOBody struct {
+	Name   string  `json:"name" validate:"min=1,max=100"`
+	Target float64 `json:"target" validate:"gt=0,lt=100,permille"`
+	Window string  `json:"window" validate:"omitempty,oneof=rolling calendar"`
+	// Length of a rolling window, 30 by default
+	WindowDays         int    `json:"windowDays" validate:"omitempty,min=1"`
+	LatencyThresholdMS *int64 `json:"latencyThresholdMs" validate:"omitempty,min=1,max=60000"`
+}
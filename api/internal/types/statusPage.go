@@ -0,0 +1,42 @@
+package types
+
+import "github.com/DevanshBhavsar3/echo/common/db/store"
+
+type StatusPageWebsiteBody struct {
+	WebsiteID   string `json:"websiteId" validate:"uuid"`
+	DisplayName string `json:"displayName" validate:"min=1,max=100"`
+	ShowUrl     bool   `json:"showUrl"`
+}
+
+type StatusPageBody struct {
+	Slug        string                  `json:"slug" validate:"min=3,max=50,slug"`
+	Title       string                  `json:"title" validate:"min=1,max=100"`
+	Description string                  `json:"description" validate:"max=500"`
+	Websites    []StatusPageWebsiteBody `json:"websites" validate:"min=1,max=50,unique=WebsiteID,dive"`
+}
+
+// Public status page responses never include website ids, and only include the url
+// when the owner chose to show it.
+type PublicStatusPageWebsite struct {
+	Name   string              `json:"name"`
+	Url    string              `json:"url,omitempty"`
+	Status string              `json:"status"`
+	Uptime []store.DailyUptime `json:"uptime"`
+}
+
+type PublicIncident struct {
+	Website         string   `json:"website"`
+	Status          string   `json:"status"`
+	AffectedRegions []string `json:"affectedRegions"`
+	StartedAt       string   `json:"startedAt"`
+	ResolvedAt      string   `json:"resolvedAt,omitempty"`
+	DurationSeconds int64    `json:"durationSeconds"`
+}
+
+type PublicStatusPageResponse struct {
+	Title       string                    `json:"title"`
+	Description string                    `json:"description"`
+	Status      string                    `json:"status"`
+	Websites    []PublicStatusPageWebsite `json:"websites"`
+	Incidents   []PublicIncident          `json:"incidents"`
+}
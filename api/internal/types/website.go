@@ -1,39 +1,155 @@
 package types
 
-import "github.com/DevanshBhavsar3/echo/common/db/store"
+import (
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+type StatusRangeBody struct {
+	Min int `json:"min" validate:"min=100,max=599"`
+	Max int `json:"max" validate:"min=100,max=599,gtefield=Min"`
+}
+
+type AssertionBody struct {
+	Type  string `json:"type" validate:"oneof=contains regex json_path"`
+	Path  string `json:"path" validate:"required_if=Type json_path,max=255"`
+	Value string `json:"value" validate:"max=1024"`
+}
+
+type DNSSpecBody struct {
+	RecordType string `json:"recordType" validate:"oneof=A AAAA CNAME MX NS TXT"`
+	Expected   string `json:"expected" validate:"max=255"`
+	Resolver   string `json:"resolver" validate:"omitempty,hostname_port"`
+}
+
+type TLSSpecBody struct {
+	ServerName string `json:"serverName" validate:"omitempty,hostname_rfc1123"`
+}
+
+type ContentSpecBody struct {
+	Selector  string   `json:"selector" validate:"max=255"`
+	Regex     string   `json:"regex" validate:"max=255"`
+	Required  []string `json:"required" validate:"max=20,dive,required,max=255"`
+	Forbidden []string `json:"forbidden" validate:"max=20,dive,required,max=255"`
+}
+
+type ExtractionBody struct {
+	Name string `json:"name" validate:"required,max=64,alphanum"`
+	From string `json:"from" validate:"oneof=json_path header regex"`
+	Path string `json:"path" validate:"required,max=255"`
+}
+
+type StepBody struct {
+	Name           string            `json:"name" validate:"required,max=100"`
+	Method         string            `json:"method" validate:"omitempty,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+	Url            string            `json:"url" validate:"required,max=2048"`
+	Headers        map[string]string `json:"headers" validate:"max=20"`
+	Body           string            `json:"body" validate:"max=65536"`
+	AcceptedStatus []StatusRangeBody `json:"acceptedStatus" validate:"max=10,dive"`
+	Assertions     []AssertionBody   `json:"assertions" validate:"max=10,dive"`
+	Extract        []ExtractionBody  `json:"extract" validate:"max=10,dive"`
+}
+
+type HeartbeatSpecBody struct {
+	GraceSeconds int64 `json:"graceSeconds" validate:"min=0,max=86400"`
+}
+
+// CheckSpecBody holds the options of every check type. Method, body and assertions
+// are for http checks only, headers and accepted status for http and content checks.
+type CheckSpecBody struct {
+	Method         string             `json:"method" validate:"omitempty,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+	Headers        map[string]string  `json:"headers" validate:"max=20"`
+	Body           string             `json:"body" validate:"max=65536"`
+	TimeoutMS      int64              `json:"timeoutMs" validate:"omitempty,min=100,max=30000"`
+	AcceptedStatus []StatusRangeBody  `json:"acceptedStatus" validate:"max=10,dive"`
+	Assertions     []AssertionBody    `json:"assertions" validate:"max=10,dive"`
+	DNS            *DNSSpecBody       `json:"dns"`
+	TLS            *TLSSpecBody       `json:"tls"`
+	Content        *ContentSpecBody   `json:"content"`
+	Steps          []StepBody         `json:"steps" validate:"max=10,dive"`
+	Heartbeat      *HeartbeatSpecBody `json:"heartbeat"`
+}
 
 type AddWebsiteBody struct {
-	Url       string   `json:"url" validate:"url"`
-	Frequency string   `json:"frequency" validate:"oneof=30s 1m 3m 5m"`
-	Regions   []string `json:"regions" validate:"min=1,dive,iso3166_1_alpha2"`
+	Url       string        `json:"url" validate:"required,max=2048"`
+	Type      string        `json:"type" validate:"omitempty,oneof=http tcp dns tls content multistep heartbeat"`
+	Frequency string        `json:"frequency" validate:"frequency"`
+	Regions   []string      `json:"regions" validate:"min=1,dive,iso3166_1_alpha2"`
+	Check     CheckSpecBody `json:"check"`
+	// Days before expiry a certificate is in the warning state, 14 by default
+	CertificateExpiryDays int `json:"certificateExpiryDays" validate:"omitempty,min=1,max=365"`
 }
 
 type AddWebsiteResponse struct {
 	Id string `json:"id"`
+	// Ingestion token of heartbeat monitors, only returned on creation
+	HeartbeatToken string `json:"heartbeatToken,omitempty"`
 }
 
 type WebsiteWithTicks struct {
 	ID        string              `json:"id"`
 	Url       string              `json:"url"`
+	Type      store.CheckType     `json:"type"`
 	Frequency string              `json:"frequency"`
 	Regions   []store.Region      `json:"regions"`
 	CreatedAt string              `json:"createdAt"`
 	Ticks     []store.WebsiteTick `json:"ticks"`
+	Paused    bool                `json:"paused"`
+	ResumeAt  *time.Time          `json:"resumeAt"`
 }
 
 type GetAllWebsitesResponse = []WebsiteWithTicks
 
 type GetWebsiteByIdResponse struct {
-	ID        string         `json:"id"`
-	Url       string         `json:"url"`
-	Frequency string         `json:"frequency"`
-	Regions   []store.Region `json:"regions"`
-	CreatedAt string         `json:"createdAt"`
-	Uptime    []store.Uptime `json:"uptime"`
+	ID        string          `json:"id"`
+	Url       string          `json:"url"`
+	Type      store.CheckType `json:"type"`
+	Frequency string          `json:"frequency"`
+	Regions   []store.Region  `json:"regions"`
+	CreatedAt string          `json:"createdAt"`
+	Uptime    []store.Uptime  `json:"uptime"`
+	Check     store.CheckSpec `json:"check"`
+	Paused    bool            `json:"paused"`
+	PausedAt  *time.Time      `json:"pausedAt"`
+	ResumeAt  *time.Time      `json:"resumeAt"`
+
+	CertificateExpiryDays int                  `json:"certificateExpiryDays"`
+	Certificate           *CertificateResponse `json:"certificate"`
+}
+
+// A paused website resumes at ResumeAt when set, or when resumed otherwise.
+type PauseWebsiteBody struct {
+	ResumeAt *time.Time `json:"resumeAt"`
+}
+
+type CertificateResponse struct {
+	store.WebsiteCertificate
+	DaysUntilExpiry int    `json:"daysUntilExpiry"`
+	State           string `json:"state"`
 }
 
 type UpdateWebsiteBody struct {
-	Url       string   `json:"url" validate:"url"`
-	Frequency string   `json:"frequency" validate:"oneof=30s 1m 3m 5m"`
-	Regions   []string `json:"regions" validate:"min=1,dive,iso3166_1_alpha2"`
+	Url       string        `json:"url" validate:"required,max=2048"`
+	Type      string        `json:"type" validate:"omitempty,oneof=http tcp dns tls content multistep heartbeat"`
+	Frequency string        `json:"frequency" validate:"frequency"`
+	Regions   []string      `json:"regions" validate:"min=1,dive,iso3166_1_alpha2"`
+	Check     CheckSpecBody `json:"check"`
+	// Days before expiry a certificate is in the warning state, 14 by default
+	CertificateExpiryDays int `json:"certificateExpiryDays" validate:"omitempty,min=1,max=365"`
+}
+
+// TicksResponse holds the tick series of several regions, sharing a bucket size.
+type TicksResponse struct {
+	From          time.Time               `json:"from"`
+	To            time.Time               `json:"to"`
+	BucketSeconds int64                   `json:"bucketSeconds"`
+	Regions       map[string][]store.Tick `json:"regions"`
+}
+
+// MetricsResponse holds the metrics of several regions over the same range.
+type MetricsResponse struct {
+	From    time.Time                 `json:"from"`
+	To      time.Time                 `json:"to"`
+	Regions map[string]*store.Metrics `json:"regions"`
 }
@@ -15,11 +15,12 @@ var (
 )
 
 type JWTPayload struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Image   string `json:"image"`
-	IsAdmin bool   `json:"is_admin"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	Image          string `json:"image"`
+	IsAdmin        bool   `json:"is_admin"`
+	OrganizationID string `json:"organization_id"`
 }
 
 func init() {
@@ -28,6 +29,17 @@ func init() {
 	Iss = "echo-api"
 }
 
+func NewClaims(payload JWTPayload) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": payload,
+		"exp": time.Now().Add(Exp).Unix(),
+		"iat": time.Now().Unix(),
+		"nbf": time.Now().Unix(),
+		"iss": Iss,
+		"aud": Iss,
+	}
+}
+
 func GenerateJWT(claims jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
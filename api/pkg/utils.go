@@ -1,6 +1,10 @@
 package pkg
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"net/http"
 	"strings"
@@ -36,3 +40,22 @@ func SendRequest(req *http.Request) ([]byte, error) {
 
 	return userData, nil
 }
+
+// GenerateToken returns a random url safe token and its sha256 hash for storage.
+func GenerateToken() (token string, hash string, err error) {
+	data := make([]byte, 32)
+
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(data)
+
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
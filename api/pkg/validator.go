@@ -1,5 +1,43 @@
 package pkg
 
-import "github.com/go-playground/validator/v10"
+import (
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 var Validate = validator.New()
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+var (
+	MinFrequency = time.Second * 10
+	MaxFrequency = time.Hour * 24
+)
+
+func init() {
+	//nolint:errcheck
+	Validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slugRegex.MatchString(fl.Field().String())
+	})
+
+	// Check intervals in whole seconds between MinFrequency and MaxFrequency
+	//nolint:errcheck
+	Validate.RegisterValidation("frequency", func(fl validator.FieldLevel) bool {
+		d, err := time.ParseDuration(fl.Field().String())
+		if err != nil {
+			return false
+		}
+
+		return d >= MinFrequency && d <= MaxFrequency && d%time.Second == 0
+	})
+
+	// Percentages stored with at most 3 decimals, like the targets of SLOs
+	//nolint:errcheck
+	Validate.RegisterValidation("permille", func(fl validator.FieldLevel) bool {
+		v := fl.Field().Float() * 1000
+		return math.Abs(v-math.Round(v)) < 1e-6
+	})
+}
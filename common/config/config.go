@@ -1,7 +1,13 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,11 +18,42 @@ func init() {
 	var err error
 
 	cfg, err = godotenv.Read()
-	if err != nil {
+	if err == nil {
+		return
+	}
+
+	// Without .env, as in tests and containers configured through the environment,
+	// the variables of the process are used
+	if !errors.Is(err, fs.ErrNotExist) {
 		log.Fatalf("error loading .env:\n%v", err)
 	}
+
+	cfg = map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			cfg[key] = value
+		}
+	}
 }
 
 func Get(key string) string {
 	return cfg[key]
 }
+
+func GetInt(key string, fallback int) int {
+	value, err := strconv.Atoi(cfg[key])
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(cfg[key])
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
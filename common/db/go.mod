@@ -5,6 +5,7 @@ go 1.24.4
 require (
 	github.com/DevanshBhavsar3/echo/common/config v0.0.0-00010101000000-000000000000
 	github.com/jackc/pgx/v5 v5.7.5
+	github.com/robfig/cron/v3 v3.0.1
 	golang.org/x/crypto v0.39.0
 )
 
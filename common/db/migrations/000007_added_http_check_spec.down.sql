@@ -0,0 +1,5 @@
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "failed_assertion";
+
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "check_spec";
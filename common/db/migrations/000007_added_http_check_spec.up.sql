@@ -0,0 +1,5 @@
+ALTER TABLE "website"
+ADD "check_spec" JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE "website_tick"
+ADD "failed_assertion" TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "incident";
+DROP TYPE IF EXISTS "incident_status";
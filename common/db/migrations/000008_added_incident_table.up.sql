@@ -0,0 +1,27 @@
+CREATE TYPE "incident_status" AS ENUM ('open', 'acknowledged', 'resolved');
+
+CREATE TABLE "incident" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "website_id" UUID NOT NULL,
+    "status" "incident_status" NOT NULL DEFAULT 'open',
+    "affected_regions" TEXT[] NOT NULL DEFAULT '{}',
+    "started_at" TIMESTAMPTZ NOT NULL,
+    "acknowledged_at" TIMESTAMPTZ,
+    "acknowledged_by" UUID,
+    "resolved_at" TIMESTAMPTZ,
+    "resolved_by" UUID,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+
+    FOREIGN KEY ("acknowledged_by")
+        REFERENCES "user"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+
+    FOREIGN KEY ("resolved_by")
+        REFERENCES "user"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+-- A website can only have one unresolved incident at a time
+CREATE UNIQUE INDEX incident_website_id_unresolved_idx ON "incident" ("website_id") WHERE "status" <> 'resolved';
+
+CREATE INDEX incident_website_id_started_at_idx ON "incident" ("website_id", "started_at" DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "notification_delivery";
+DROP TYPE IF EXISTS "notification_delivery_status";
+DROP TABLE IF EXISTS "website_notification_channel";
+DROP TABLE IF EXISTS "notification_channel";
+DROP TYPE IF EXISTS "notification_channel_type";
@@ -0,0 +1,52 @@
+CREATE TYPE "notification_channel_type" AS ENUM ('webhook', 'email', 'slack', 'discord');
+
+CREATE TABLE "notification_channel" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+    "type" "notification_channel_type" NOT NULL,
+    "config" JSONB NOT NULL DEFAULT '{}',
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("user_id")
+        REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "website_notification_channel" (
+    "website_id" UUID NOT NULL,
+    "channel_id" UUID NOT NULL,
+
+    PRIMARY KEY ("website_id", "channel_id"),
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("channel_id")
+        REFERENCES notification_channel("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TYPE "notification_delivery_status" AS ENUM ('pending', 'delivered', 'failed');
+
+CREATE TABLE "notification_delivery" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "channel_id" UUID NOT NULL,
+    "website_id" UUID NOT NULL,
+    "incident_id" UUID,
+    "event" TEXT NOT NULL,
+    "status" "notification_delivery_status" NOT NULL DEFAULT 'pending',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "error" TEXT,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "delivered_at" TIMESTAMPTZ,
+
+    FOREIGN KEY ("channel_id")
+        REFERENCES notification_channel("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("incident_id")
+        REFERENCES incident("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX notification_delivery_channel_id_created_at_idx ON "notification_delivery" ("channel_id", "created_at" DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "status_page_website";
+DROP TABLE IF EXISTS "status_page";
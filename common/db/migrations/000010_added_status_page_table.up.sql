@@ -0,0 +1,28 @@
+CREATE TABLE "status_page" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "user_id" UUID NOT NULL,
+    "slug" TEXT UNIQUE NOT NULL,
+    "title" TEXT NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("user_id")
+        REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "status_page_website" (
+    "status_page_id" UUID NOT NULL,
+    "website_id" UUID NOT NULL,
+    "display_name" TEXT NOT NULL,
+    "show_url" BOOLEAN NOT NULL DEFAULT FALSE,
+    "position" INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY ("status_page_id", "website_id"),
+
+    FOREIGN KEY ("status_page_id")
+        REFERENCES status_page("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS website_organization_id_idx;
+
+ALTER TABLE "notification_channel"
+DROP CONSTRAINT IF EXISTS notification_channel_organization_id_fkey,
+DROP COLUMN IF EXISTS "organization_id";
+
+ALTER TABLE "status_page"
+DROP CONSTRAINT IF EXISTS status_page_organization_id_fkey,
+DROP COLUMN IF EXISTS "organization_id";
+
+ALTER TABLE "website"
+DROP CONSTRAINT IF EXISTS website_organization_id_fkey,
+DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS "organization_invite";
+DROP TABLE IF EXISTS "organization_member";
+DROP TABLE IF EXISTS "organization";
+DROP TYPE IF EXISTS "organization_role";
@@ -0,0 +1,100 @@
+CREATE TYPE "organization_role" AS ENUM ('owner', 'admin', 'editor', 'viewer');
+
+CREATE TABLE "organization" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "name" TEXT NOT NULL,
+    "personal" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_by" UUID NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("created_by")
+        REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "organization_member" (
+    "organization_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "role" "organization_role" NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY ("organization_id", "user_id"),
+
+    FOREIGN KEY ("organization_id")
+        REFERENCES organization("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("user_id")
+        REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE "organization_invite" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "organization_id" UUID NOT NULL,
+    "email" TEXT NOT NULL,
+    "role" "organization_role" NOT NULL,
+    "token_hash" TEXT UNIQUE NOT NULL,
+    "invited_by" UUID,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "accepted_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("organization_id")
+        REFERENCES organization("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("invited_by")
+        REFERENCES "user"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+-- Every existing user gets a personal organization owning their data
+INSERT INTO "organization" (name, personal, created_by)
+SELECT u.name, TRUE, u.id
+FROM "user" u;
+
+INSERT INTO "organization_member" (organization_id, user_id, role)
+SELECT o.id, o.created_by, 'owner'
+FROM "organization" o
+WHERE o.personal;
+
+ALTER TABLE "website"
+ADD "organization_id" UUID;
+
+UPDATE "website" w
+SET organization_id = o.id
+FROM "organization" o
+WHERE o.personal AND o.created_by = w.created_by;
+
+ALTER TABLE "website"
+ALTER COLUMN "organization_id" SET NOT NULL,
+
+ADD CONSTRAINT website_organization_id_fkey
+FOREIGN KEY ("organization_id") REFERENCES "organization"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+ALTER TABLE "status_page"
+ADD "organization_id" UUID;
+
+UPDATE "status_page" sp
+SET organization_id = o.id
+FROM "organization" o
+WHERE o.personal AND o.created_by = sp.user_id;
+
+ALTER TABLE "status_page"
+ALTER COLUMN "organization_id" SET NOT NULL,
+
+ADD CONSTRAINT status_page_organization_id_fkey
+FOREIGN KEY ("organization_id") REFERENCES "organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "notification_channel"
+ADD "organization_id" UUID;
+
+UPDATE "notification_channel" nc
+SET organization_id = o.id
+FROM "organization" o
+WHERE o.personal AND o.created_by = nc.user_id;
+
+ALTER TABLE "notification_channel"
+ALTER COLUMN "organization_id" SET NOT NULL,
+
+ADD CONSTRAINT notification_channel_organization_id_fkey
+FOREIGN KEY ("organization_id") REFERENCES "organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX website_organization_id_idx ON "website" ("organization_id");
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS website_tick_identity_idx;
+
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "checked_at";
@@ -0,0 +1,13 @@
+ALTER TABLE "website_tick"
+ADD "checked_at" TIMESTAMPTZ;
+
+-- A tick is identified by its website, region and scheduled time
+DELETE FROM "website_tick" a
+USING "website_tick" b
+WHERE
+    a.website_id = b.website_id
+    AND a.region_id = b.region_id
+    AND a.time = b.time
+    AND a.ctid < b.ctid;
+
+CREATE UNIQUE INDEX website_tick_identity_idx ON "website_tick" ("website_id", "region_id", "time");
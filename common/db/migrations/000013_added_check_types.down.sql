@@ -0,0 +1,7 @@
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "result";
+
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "check_type";
+
+DROP TYPE IF EXISTS "check_type";
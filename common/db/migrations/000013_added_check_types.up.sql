@@ -0,0 +1,7 @@
+CREATE TYPE "check_type" AS ENUM ('http', 'tcp', 'dns', 'tls');
+
+ALTER TABLE "website"
+ADD "check_type" "check_type" NOT NULL DEFAULT 'http';
+
+ALTER TABLE "website_tick"
+ADD "result" JSONB;
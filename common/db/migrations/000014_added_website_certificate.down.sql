@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "website_certificate";
+
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "certificate_expiry_days";
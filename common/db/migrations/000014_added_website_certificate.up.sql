@@ -0,0 +1,18 @@
+ALTER TABLE "website"
+ADD "certificate_expiry_days" INTEGER NOT NULL DEFAULT 14;
+
+CREATE TABLE "website_certificate" (
+    "website_id" UUID PRIMARY KEY,
+    "subject" TEXT NOT NULL,
+    "issuer" TEXT NOT NULL,
+    "dns_names" TEXT[] NOT NULL DEFAULT '{}',
+    "not_before" TIMESTAMPTZ NOT NULL,
+    "not_after" TIMESTAMPTZ NOT NULL,
+    "chain" JSONB NOT NULL,
+    "checked_at" TIMESTAMPTZ NOT NULL,
+    -- Set once the expiry warning of this certificate was sent
+    "warned_at" TIMESTAMPTZ,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
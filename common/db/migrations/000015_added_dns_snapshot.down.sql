@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "dns_snapshot";
+
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "dns_pins";
@@ -0,0 +1,23 @@
+-- Records pinned by the user, by record type. Resolved records that differ are drift.
+ALTER TABLE "website"
+ADD "dns_pins" JSONB NOT NULL DEFAULT '{}';
+
+-- A snapshot is only stored when the records or their drift change, so the table
+-- is the change history of a website in each region.
+CREATE TABLE "dns_snapshot" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "website_id" UUID NOT NULL,
+    "region_id" UUID NOT NULL,
+    "records" JSONB NOT NULL,
+    "drift" TEXT[] NOT NULL DEFAULT '{}',
+    "observed_at" TIMESTAMPTZ NOT NULL,
+    "last_seen_at" TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("region_id")
+        REFERENCES region("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX dns_snapshot_website_id_observed_at_idx ON "dns_snapshot" ("website_id", "observed_at" DESC);
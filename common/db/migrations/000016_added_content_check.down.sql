@@ -0,0 +1,10 @@
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "content_checked_at",
+DROP COLUMN IF EXISTS "content_hash";
+
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "content_snippet",
+DROP COLUMN IF EXISTS "content_hash";
+
+-- Postgres can't drop an enum value, content checks fall back to http
+UPDATE "website" SET check_type = 'http' WHERE check_type = 'content';
@@ -0,0 +1,10 @@
+ALTER TYPE "check_type" ADD VALUE IF NOT EXISTS 'content';
+
+ALTER TABLE "website_tick"
+ADD "content_hash" TEXT,
+ADD "content_snippet" TEXT;
+
+-- Latest content hash of the website, to detect changes across regions
+ALTER TABLE "website"
+ADD "content_hash" TEXT,
+ADD "content_checked_at" TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- Postgres can't drop an enum value, multistep checks fall back to http
+UPDATE "website" SET check_type = 'http', check_spec = check_spec - 'steps' WHERE check_type = 'multistep';
@@ -0,0 +1,2 @@
+-- Steps and their results live in the check_spec of the website and the result of the tick
+ALTER TYPE "check_type" ADD VALUE IF NOT EXISTS 'multistep';
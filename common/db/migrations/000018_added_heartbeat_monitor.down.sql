@@ -0,0 +1,7 @@
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "heartbeat_started_at",
+DROP COLUMN IF EXISTS "heartbeat_at",
+DROP COLUMN IF EXISTS "heartbeat_token_hash";
+
+-- Postgres can't drop an enum value, heartbeat monitors fall back to http
+UPDATE "website" SET check_type = 'http' WHERE check_type = 'heartbeat';
@@ -0,0 +1,8 @@
+ALTER TYPE "check_type" ADD VALUE IF NOT EXISTS 'heartbeat';
+
+ALTER TABLE "website"
+ADD "heartbeat_token_hash" TEXT UNIQUE,
+-- Last success or fail ping
+ADD "heartbeat_at" TIMESTAMPTZ,
+-- Start ping of the run in progress
+ADD "heartbeat_started_at" TIMESTAMPTZ;
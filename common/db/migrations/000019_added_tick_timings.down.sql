@@ -0,0 +1,6 @@
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "transfer_ms",
+DROP COLUMN IF EXISTS "ttfb_ms",
+DROP COLUMN IF EXISTS "tls_ms",
+DROP COLUMN IF EXISTS "connect_ms",
+DROP COLUMN IF EXISTS "dns_ms";
@@ -0,0 +1,7 @@
+-- Phase timings of http checks, null for other checks, reused connections and older ticks
+ALTER TABLE "website_tick"
+ADD "dns_ms" INTEGER,
+ADD "connect_ms" INTEGER,
+ADD "tls_ms" INTEGER,
+ADD "ttfb_ms" INTEGER,
+ADD "transfer_ms" INTEGER;
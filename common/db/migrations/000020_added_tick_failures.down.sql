@@ -0,0 +1,4 @@
+ALTER TABLE "website_tick"
+DROP COLUMN IF EXISTS "error_message",
+DROP COLUMN IF EXISTS "error_kind",
+DROP COLUMN IF EXISTS "http_status_code";
@@ -0,0 +1,5 @@
+-- Why a check failed, null for successful checks and older ticks
+ALTER TABLE "website_tick"
+ADD "http_status_code" INTEGER,
+ADD "error_kind" TEXT,
+ADD "error_message" TEXT;
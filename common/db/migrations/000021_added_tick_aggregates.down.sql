@@ -0,0 +1,9 @@
+SELECT remove_retention_policy('website_tick', if_exists => true);
+SELECT remove_compression_policy('website_tick', if_exists => true);
+
+SELECT decompress_chunk(c, true) FROM show_chunks('website_tick') c;
+ALTER TABLE "website_tick" SET (timescaledb.compress = false);
+
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_1d";
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_1h";
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_5m";
@@ -0,0 +1,136 @@
+-- Rollups of website_tick for dashboard queries. The hourly and daily aggregates
+-- are built on the 5 minute one. response_time_le_N counts the checks answered in
+-- at most N ms, the buckets of the response time histogram used for percentiles,
+-- and must match store.ResponseTimeBuckets.
+CREATE MATERIALIZED VIEW "website_tick_5m"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('5 minutes', time) AS bucket,
+    website_id,
+    region_id,
+    COUNT(*) AS checks,
+    SUM((status = 'up')::int) AS up,
+    SUM((status = 'down')::int) AS down,
+    SUM(response_time_ms) AS response_time_sum,
+    COUNT(response_time_ms) AS response_time_count,
+    MAX(response_time_ms) AS response_time_max,
+    SUM((response_time_ms <= 25)::int) AS response_time_le_25,
+    SUM((response_time_ms <= 50)::int) AS response_time_le_50,
+    SUM((response_time_ms <= 100)::int) AS response_time_le_100,
+    SUM((response_time_ms <= 250)::int) AS response_time_le_250,
+    SUM((response_time_ms <= 500)::int) AS response_time_le_500,
+    SUM((response_time_ms <= 1000)::int) AS response_time_le_1000,
+    SUM((response_time_ms <= 2500)::int) AS response_time_le_2500,
+    SUM((response_time_ms <= 5000)::int) AS response_time_le_5000,
+    SUM((response_time_ms <= 10000)::int) AS response_time_le_10000,
+    SUM(dns_ms) AS dns_sum,
+    COUNT(dns_ms) AS dns_count,
+    SUM(connect_ms) AS connect_sum,
+    COUNT(connect_ms) AS connect_count,
+    SUM(tls_ms) AS tls_sum,
+    COUNT(tls_ms) AS tls_count,
+    SUM(ttfb_ms) AS ttfb_sum,
+    COUNT(ttfb_ms) AS ttfb_count,
+    SUM(transfer_ms) AS transfer_sum,
+    COUNT(transfer_ms) AS transfer_count
+FROM "website_tick"
+GROUP BY time_bucket('5 minutes', time), website_id, region_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW "website_tick_1h"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 hour', bucket) AS bucket,
+    website_id,
+    region_id,
+    SUM(checks) AS checks,
+    SUM(up) AS up,
+    SUM(down) AS down,
+    SUM(response_time_sum) AS response_time_sum,
+    SUM(response_time_count) AS response_time_count,
+    MAX(response_time_max) AS response_time_max,
+    SUM(response_time_le_25) AS response_time_le_25,
+    SUM(response_time_le_50) AS response_time_le_50,
+    SUM(response_time_le_100) AS response_time_le_100,
+    SUM(response_time_le_250) AS response_time_le_250,
+    SUM(response_time_le_500) AS response_time_le_500,
+    SUM(response_time_le_1000) AS response_time_le_1000,
+    SUM(response_time_le_2500) AS response_time_le_2500,
+    SUM(response_time_le_5000) AS response_time_le_5000,
+    SUM(response_time_le_10000) AS response_time_le_10000,
+    SUM(dns_sum) AS dns_sum,
+    SUM(dns_count) AS dns_count,
+    SUM(connect_sum) AS connect_sum,
+    SUM(connect_count) AS connect_count,
+    SUM(tls_sum) AS tls_sum,
+    SUM(tls_count) AS tls_count,
+    SUM(ttfb_sum) AS ttfb_sum,
+    SUM(ttfb_count) AS ttfb_count,
+    SUM(transfer_sum) AS transfer_sum,
+    SUM(transfer_count) AS transfer_count
+FROM "website_tick_5m"
+GROUP BY time_bucket('1 hour', bucket), website_id, region_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW "website_tick_1d"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 day', bucket) AS bucket,
+    website_id,
+    region_id,
+    SUM(checks) AS checks,
+    SUM(up) AS up,
+    SUM(down) AS down,
+    SUM(response_time_sum) AS response_time_sum,
+    SUM(response_time_count) AS response_time_count,
+    MAX(response_time_max) AS response_time_max,
+    SUM(response_time_le_25) AS response_time_le_25,
+    SUM(response_time_le_50) AS response_time_le_50,
+    SUM(response_time_le_100) AS response_time_le_100,
+    SUM(response_time_le_250) AS response_time_le_250,
+    SUM(response_time_le_500) AS response_time_le_500,
+    SUM(response_time_le_1000) AS response_time_le_1000,
+    SUM(response_time_le_2500) AS response_time_le_2500,
+    SUM(response_time_le_5000) AS response_time_le_5000,
+    SUM(response_time_le_10000) AS response_time_le_10000,
+    SUM(dns_sum) AS dns_sum,
+    SUM(dns_count) AS dns_count,
+    SUM(connect_sum) AS connect_sum,
+    SUM(connect_count) AS connect_count,
+    SUM(tls_sum) AS tls_sum,
+    SUM(tls_count) AS tls_count,
+    SUM(ttfb_sum) AS ttfb_sum,
+    SUM(ttfb_count) AS ttfb_count,
+    SUM(transfer_sum) AS transfer_sum,
+    SUM(transfer_count) AS transfer_count
+FROM "website_tick_1h"
+GROUP BY time_bucket('1 day', bucket), website_id, region_id
+WITH NO DATA;
+
+-- Aggregates include ticks not materialized yet, so policies only refresh recent
+-- buckets. Later ticks than start_offset, from a long outage of the db-worker, need
+-- a manual refresh_continuous_aggregate.
+SELECT add_continuous_aggregate_policy('website_tick_5m',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '5 minutes',
+    schedule_interval => INTERVAL '5 minutes');
+
+SELECT add_continuous_aggregate_policy('website_tick_1h',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '30 minutes');
+
+SELECT add_continuous_aggregate_policy('website_tick_1d',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour');
+
+-- Raw ticks are compressed after a week and kept until a retention is set with
+-- make tick-policies. Retention of the aggregates is added once they are refreshed.
+ALTER TABLE "website_tick" SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'website_id',
+    timescaledb.compress_orderby = 'time DESC'
+);
+
+SELECT add_compression_policy('website_tick', INTERVAL '7 days');
@@ -0,0 +1,3 @@
+-- Materializes the ticks stored before the aggregate existed. A refresh can't run
+-- in a transaction, so it has a migration of its own.
+CALL refresh_continuous_aggregate('website_tick_1h', NULL, NULL);
@@ -0,0 +1 @@
+-- Nothing to undo, the aggregate is dropped by the previous migration
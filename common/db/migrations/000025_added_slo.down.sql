@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "website_slo";
+
+DROP TYPE IF EXISTS "slo_state";
+
+DROP TYPE IF EXISTS "slo_window";
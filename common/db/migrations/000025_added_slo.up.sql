@@ -0,0 +1,25 @@
+CREATE TYPE "slo_window" AS ENUM ('rolling', 'calendar');
+
+CREATE TYPE "slo_state" AS ENUM ('ok', 'slow_burn', 'fast_burn', 'exhausted');
+
+CREATE TABLE "website_slo" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "website_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+    -- Percentage of good checks, like 99.9
+    "target" NUMERIC(6,3) NOT NULL CHECK ("target" > 0 AND "target" < 100),
+    "window" "slo_window" NOT NULL DEFAULT 'rolling',
+    -- Length of a rolling window, calendar windows are the current month
+    "window_days" INTEGER NOT NULL DEFAULT 30,
+    -- Up checks slower than this are bad
+    "latency_threshold_ms" INTEGER,
+    -- Burn state of the last evaluation by the db-worker
+    "state" "slo_state" NOT NULL DEFAULT 'ok',
+    "state_changed_at" TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX website_slo_website_id_idx ON "website_slo" ("website_id");
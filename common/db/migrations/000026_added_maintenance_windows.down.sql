@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "maintenance_window_website";
+
+DROP TABLE IF EXISTS "maintenance_window";
+
+-- Enum values can't be dropped, maintenance ticks count as unknown again
+UPDATE "website_tick" SET status = 'unknown' WHERE status = 'maintenance';
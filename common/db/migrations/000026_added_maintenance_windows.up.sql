@@ -0,0 +1,38 @@
+ALTER TYPE "website_status" ADD VALUE IF NOT EXISTS 'maintenance';
+
+CREATE TABLE "maintenance_window" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "organization_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+    -- Start of a one-off window, or of the first occurrence of a recurring one
+    "starts_at" TIMESTAMPTZ NOT NULL,
+    "duration_seconds" INTEGER NOT NULL CHECK ("duration_seconds" > 0),
+    -- Cron expression of a recurring window, evaluated in the timezone
+    "recurrence" TEXT,
+    -- No occurrence of a recurring window starts after until
+    "until" TIMESTAMPTZ,
+    "timezone" TEXT NOT NULL DEFAULT 'UTC',
+    -- The publisher doesn't check websites during the window
+    "skip_checks" BOOLEAN NOT NULL DEFAULT FALSE,
+    -- The window covers every website of the organization
+    "all_websites" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY ("organization_id")
+        REFERENCES organization("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX maintenance_window_organization_id_idx ON "maintenance_window" ("organization_id");
+
+CREATE TABLE "maintenance_window_website" (
+    "window_id" UUID NOT NULL,
+    "website_id" UUID NOT NULL,
+
+    PRIMARY KEY ("window_id", "website_id"),
+
+    FOREIGN KEY ("window_id")
+        REFERENCES maintenance_window("id") ON DELETE CASCADE ON UPDATE CASCADE,
+
+    FOREIGN KEY ("website_id")
+        REFERENCES website("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_maintenance_1d";
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_maintenance_1h";
+DROP MATERIALIZED VIEW IF EXISTS "website_tick_maintenance_5m";
@@ -0,0 +1,53 @@
+-- Maintenance ticks are counted by aggregates of their own, with the buckets of the
+-- tick aggregates. Queries join them to the tick aggregates, which are left as they
+-- are so their history is kept. Only buckets with maintenance ticks have rows, and
+-- there are none before the maintenance status was added, so the aggregates start
+-- empty. Their retention is added with the one of the tick aggregates.
+CREATE MATERIALIZED VIEW "website_tick_maintenance_5m"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('5 minutes', time) AS bucket,
+    website_id,
+    region_id,
+    COUNT(*) AS maintenance
+FROM "website_tick"
+WHERE status = 'maintenance'
+GROUP BY time_bucket('5 minutes', time), website_id, region_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW "website_tick_maintenance_1h"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 hour', bucket) AS bucket,
+    website_id,
+    region_id,
+    SUM(maintenance) AS maintenance
+FROM "website_tick_maintenance_5m"
+GROUP BY time_bucket('1 hour', bucket), website_id, region_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW "website_tick_maintenance_1d"
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 day', bucket) AS bucket,
+    website_id,
+    region_id,
+    SUM(maintenance) AS maintenance
+FROM "website_tick_maintenance_1h"
+GROUP BY time_bucket('1 day', bucket), website_id, region_id
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('website_tick_maintenance_5m',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '5 minutes',
+    schedule_interval => INTERVAL '5 minutes');
+
+SELECT add_continuous_aggregate_policy('website_tick_maintenance_1h',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '30 minutes');
+
+SELECT add_continuous_aggregate_policy('website_tick_maintenance_1d',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour');
@@ -0,0 +1,4 @@
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "resumed_at",
+DROP COLUMN IF EXISTS "resume_at",
+DROP COLUMN IF EXISTS "paused_at";
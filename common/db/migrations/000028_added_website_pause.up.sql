@@ -0,0 +1,6 @@
+-- A paused website isn't checked until it is resumed, or until resume_at when set.
+-- resumed_at lets heartbeat monitors count missed pings from the resume.
+ALTER TABLE "website"
+ADD "paused_at" TIMESTAMPTZ,
+ADD "resume_at" TIMESTAMPTZ,
+ADD "resumed_at" TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "website_purge_after_idx";
+
+ALTER TABLE "website"
+DROP COLUMN IF EXISTS "exported_at",
+DROP COLUMN IF EXISTS "export_on_purge",
+DROP COLUMN IF EXISTS "purge_after",
+DROP COLUMN IF EXISTS "deleted_at";
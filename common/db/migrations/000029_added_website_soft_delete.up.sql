@@ -0,0 +1,9 @@
+-- Deleted websites are kept until purge_after so they can be restored, then their
+-- ticks are purged in the background. export_on_purge archives the ticks first.
+ALTER TABLE "website"
+ADD "deleted_at" TIMESTAMPTZ,
+ADD "purge_after" TIMESTAMPTZ,
+ADD "export_on_purge" BOOLEAN NOT NULL DEFAULT false,
+ADD "exported_at" TIMESTAMPTZ;
+
+CREATE INDEX "website_purge_after_idx" ON "website" ("purge_after") WHERE "deleted_at" IS NOT NULL;
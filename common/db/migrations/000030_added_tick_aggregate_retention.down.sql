@@ -0,0 +1,5 @@
+SELECT remove_retention_policy('website_tick_maintenance_1h', if_exists => true);
+SELECT remove_retention_policy('website_tick_maintenance_5m', if_exists => true);
+
+SELECT remove_retention_policy('website_tick_1h', if_exists => true);
+SELECT remove_retention_policy('website_tick_5m', if_exists => true);
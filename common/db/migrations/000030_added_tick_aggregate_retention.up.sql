@@ -0,0 +1,9 @@
+-- Retention of the tick aggregates, added once 000022-000024 materialized the ticks
+-- stored before them so no history is dropped before it is rolled up. Availability
+-- percentiles read two months of 5 minute buckets, daily buckets are kept forever.
+-- Raw ticks are kept until a retention is set with make tick-policies.
+SELECT add_retention_policy('website_tick_5m', INTERVAL '90 days', if_not_exists => true);
+SELECT add_retention_policy('website_tick_1h', INTERVAL '2 years', if_not_exists => true);
+
+SELECT add_retention_policy('website_tick_maintenance_5m', INTERVAL '90 days', if_not_exists => true);
+SELECT add_retention_policy('website_tick_maintenance_1h', INTERVAL '2 years', if_not_exists => true);
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResponseTimeBuckets are the upper bounds in ms of the response time histogram of
+// the tick aggregates. They must match the response_time_le_N columns.
+var ResponseTimeBuckets = []int64{25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// MaxAggregateBuckets is the most buckets a query reads from an aggregate before a
+// coarser one is used.
+var MaxAggregateBuckets = 1500
+
+var (
+	// Finest bucket of a tick series, read from the raw ticks
+	MinTickBucket = time.Minute
+	// Most points of a tick series, over every region
+	MaxTickPoints = 2000
+	// Points aimed at when the bucket of a tick series is picked
+	AutoTickPoints = 500
+	// Bucket sizes picked for tick series, finest first
+	AutoTickBuckets = []time.Duration{
+		time.Minute,
+		5 * time.Minute,
+		15 * time.Minute,
+		30 * time.Minute,
+		time.Hour,
+		3 * time.Hour,
+		6 * time.Hour,
+		12 * time.Hour,
+		24 * time.Hour,
+		7 * 24 * time.Hour,
+	}
+	// Retention of the raw ticks, zero while they are kept forever. Match it to the
+	// TICK_RETENTION of make tick-policies when one is set.
+	RawTickRetention time.Duration
+)
+
+type tickAggregate struct {
+	view   string
+	bucket time.Duration
+	// Zero for aggregates kept forever
+	retention time.Duration
+}
+
+// retains reports whether the aggregate still holds the buckets from t.
+func (a tickAggregate) retains(t time.Time) bool {
+	return a.retention == 0 || time.Since(t) <= a.retention
+}
+
+// Continuous aggregates of website_tick, finest first. Retentions match the
+// policies of the migrations.
+var tickAggregates = []tickAggregate{
+	{withMaintenance("5m"), 5 * time.Minute, 90 * 24 * time.Hour},
+	{withMaintenance("1h"), time.Hour, 2 * 365 * 24 * time.Hour},
+	{withMaintenance("1d"), 24 * time.Hour, 0},
+}
+
+// dailyTicks is the aggregate of daily buckets.
+var dailyTicks = tickAggregates[len(tickAggregates)-1]
+
+// withMaintenance joins a tick aggregate with the count of maintenance ticks of its
+// buckets, kept in an aggregate of its own.
+func withMaintenance(size string) string {
+	return fmt.Sprintf(`(
+		SELECT t.*, COALESCE(m.maintenance, 0) AS maintenance
+		FROM "website_tick_%s" t
+		LEFT JOIN "website_tick_maintenance_%s" m USING (bucket, website_id, region_id)
+	)`, size, size)
+}
+
+// rawTicks shapes the raw ticks like a row of an aggregate, for buckets finer than
+// the aggregates.
+func rawTicks() tickAggregate {
+	columns := []string{
+		"time AS bucket",
+		"website_id",
+		"region_id",
+		"1 AS checks",
+		"(status = 'up')::int AS up",
+		"(status = 'down')::int AS down",
+		"(status = 'maintenance')::int AS maintenance",
+		"response_time_ms AS response_time_sum",
+		"(response_time_ms IS NOT NULL)::int AS response_time_count",
+		"response_time_ms AS response_time_max",
+	}
+	for _, b := range ResponseTimeBuckets {
+		columns = append(columns, fmt.Sprintf("(response_time_ms <= %d)::int AS response_time_le_%d", b, b))
+	}
+	for _, phase := range []string{"dns", "connect", "tls", "ttfb", "transfer"} {
+		columns = append(columns, fmt.Sprintf("%s_ms AS %s_sum, (%s_ms IS NOT NULL)::int AS %s_count", phase, phase, phase, phase))
+	}
+
+	return tickAggregate{
+		view:      fmt.Sprintf(`(SELECT %s FROM "website_tick")`, strings.Join(columns, ", ")),
+		bucket:    MinTickBucket,
+		retention: RawTickRetention,
+	}
+}
+
+// sourceFor returns the coarsest source of buckets of the given size that still
+// holds the buckets from t.
+func sourceFor(bucket time.Duration, from time.Time) (tickAggregate, error) {
+	sources := append([]tickAggregate{rawTicks()}, tickAggregates...)
+
+	for i := len(sources) - 1; i >= 0; i-- {
+		if bucket%sources[i].bucket == 0 && sources[i].retains(from) {
+			return sources[i], nil
+		}
+	}
+
+	return tickAggregate{}, ErrBucketTooFine
+}
+
+// aggregateRetaining returns the finest aggregate still holding the buckets from t.
+func aggregateRetaining(t time.Time) tickAggregate {
+	for _, a := range tickAggregates {
+		if a.retains(t) {
+			return a
+		}
+	}
+
+	return tickAggregates[len(tickAggregates)-1]
+}
+
+// TickBucket returns the bucket of a tick series of the regions over r. An explicit
+// bucket is validated, otherwise the finest bucket of about AutoTickPoints points
+// that the stored ticks still hold is picked.
+func TickBucket(r Range, regions int, bucket time.Duration) (time.Duration, error) {
+	span := r.To.Sub(r.From)
+	points := func(b time.Duration) int {
+		return int(span/b+1) * regions
+	}
+
+	if bucket != 0 {
+		if bucket < MinTickBucket || bucket%MinTickBucket != 0 {
+			return 0, ErrInvalidBucket
+		}
+		if points(bucket) > MaxTickPoints {
+			return 0, ErrTooManyPoints
+		}
+		if _, err := sourceFor(bucket, r.From); err != nil {
+			return 0, err
+		}
+
+		return bucket, nil
+	}
+
+	for _, b := range AutoTickBuckets {
+		if points(b) > AutoTickPoints {
+			continue
+		}
+		if _, err := sourceFor(b, r.From); err == nil {
+			return b, nil
+		}
+	}
+
+	// Ranges too long for the coarsest bucket are limited by MaxTickPoints
+	b := AutoTickBuckets[len(AutoTickBuckets)-1]
+	if points(b) > MaxTickPoints {
+		return 0, ErrTooManyPoints
+	}
+
+	return b, nil
+}
+
+// aggregateFor returns the finest aggregate covering span in at most
+// MaxAggregateBuckets buckets.
+func aggregateFor(span time.Duration) tickAggregate {
+	for _, a := range tickAggregates {
+		if span/a.bucket <= time.Duration(MaxAggregateBuckets) {
+			return a
+		}
+	}
+
+	return tickAggregates[len(tickAggregates)-1]
+}
+
+// histogramColumns selects the summed histogram of an aggregate, the total and
+// maximum response times last, for the rows matching filter.
+func histogramColumns(filter string) string {
+	var columns []string
+
+	for _, b := range ResponseTimeBuckets {
+		columns = append(columns, fmt.Sprintf("COALESCE(SUM(response_time_le_%d) FILTER (WHERE %s), 0)::bigint", b, filter))
+	}
+	columns = append(columns,
+		fmt.Sprintf("COALESCE(SUM(response_time_count) FILTER (WHERE %s), 0)::bigint", filter),
+		fmt.Sprintf("COALESCE(MAX(response_time_max) FILTER (WHERE %s), 0)::bigint", filter),
+	)
+
+	return strings.Join(columns, ",\n")
+}
+
+// Histogram counts response times by ResponseTimeBuckets. Cumulative[i] is the
+// number of response times at most ResponseTimeBuckets[i].
+type Histogram struct {
+	Cumulative []int64
+	Count      int64
+	Max        int64
+}
+
+func newHistogram() Histogram {
+	return Histogram{Cumulative: make([]int64, len(ResponseTimeBuckets))}
+}
+
+// targets returns the scan targets of the columns of histogramColumns.
+func (h *Histogram) targets() []any {
+	var targets []any
+
+	for i := range h.Cumulative {
+		targets = append(targets, &h.Cumulative[i])
+	}
+
+	return append(targets, &h.Count, &h.Max)
+}
+
+// Quantile estimates the q quantile by interpolating inside the bucket it falls
+// in. Response times above the last bucket are interpolated up to the maximum.
+func (h Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.Count)
+
+	var lower, below int64
+	for i, upper := range ResponseTimeBuckets {
+		if float64(h.Cumulative[i]) >= rank {
+			return interpolate(lower, upper, below, h.Cumulative[i], rank, h.Max)
+		}
+
+		lower, below = upper, h.Cumulative[i]
+	}
+
+	return interpolate(lower, max(h.Max, lower), below, h.Count, rank, h.Max)
+}
+
+func interpolate(lower, upper, below, count int64, rank float64, maximum int64) float64 {
+	value := float64(upper)
+	if count > below {
+		value = float64(lower) + float64(upper-lower)*(rank-float64(below))/float64(count-below)
+	}
+
+	return min(value, float64(maximum))
+}
+
+var (
+	ErrInvalidBucket = errors.New("bucket must be a whole number of minutes")
+	ErrTooManyPoints = errors.New("too many points for the range")
+	ErrBucketTooFine = errors.New("bucket is finer than the ticks kept for the range")
+)
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTickBucket(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	defer func(retention time.Duration) { RawTickRetention = retention }(RawTickRetention)
+	RawTickRetention = 90 * day
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		span    time.Duration
+		regions int
+		bucket  time.Duration
+		want    time.Duration
+		err     error
+	}{
+		{"auto hour", now.Add(-time.Hour), time.Hour, 1, 0, time.Minute, nil},
+		{"auto day", now.Add(-day), day, 1, 0, 5 * time.Minute, nil},
+		{"auto day of two regions", now.Add(-day), day, 2, 0, 15 * time.Minute, nil},
+		{"auto past raw retention", now.Add(-400 * day), time.Hour, 1, 0, time.Hour, nil},
+		{"auto too long", now.Add(-100 * 365 * day), 100 * 365 * day, 1, 0, 0, ErrTooManyPoints},
+		{"explicit", now.Add(-day), day, 1, time.Minute, time.Minute, nil},
+		{"explicit below a minute", now.Add(-time.Hour), time.Hour, 1, 30 * time.Second, 0, ErrInvalidBucket},
+		{"explicit partial minute", now.Add(-time.Hour), time.Hour, 1, 90 * time.Second, 0, ErrInvalidBucket},
+		{"explicit too many points", now.Add(-2 * day), 2 * day, 1, time.Minute, 0, ErrTooManyPoints},
+		{"explicit past raw retention", now.Add(-100 * day), day, 1, time.Minute, 0, ErrBucketTooFine},
+		{"explicit from an aggregate", now.Add(-100 * day), day, 1, time.Hour, time.Hour, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TickBucket(Range{From: tt.from, To: tt.from.Add(tt.span)}, tt.regions, tt.bucket)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("bucket = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// histogramOf builds the histogram of response times like the aggregates do.
+func histogramOf(times ...int64) Histogram {
+	h := newHistogram()
+
+	for _, ms := range times {
+		for i, upper := range ResponseTimeBuckets {
+			if ms <= upper {
+				h.Cumulative[i]++
+			}
+		}
+		h.Count++
+		h.Max = max(h.Max, ms)
+	}
+
+	return h
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := histogramOf(10, 40, 80, 20000)
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0.25, 25},
+		{0.5, 50},
+		{0.75, 100},
+		{0.875, 15000},
+		{1, 20000},
+	}
+
+	for _, tt := range tests {
+		if got := h.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	if got := histogramOf(10, 10).Quantile(0.99); got != 10 {
+		t.Errorf("Quantile above the maximum = %v, want 10", got)
+	}
+
+	if got := newHistogram().Quantile(0.5); got != 0 {
+		t.Errorf("Quantile of an empty histogram = %v, want 0", got)
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	CertificateValid   = "valid"
+	CertificateWarning = "warning"
+	CertificateExpired = "expired"
+)
+
+var DefaultCertificateExpiryDays = 14
+
+type Certificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dnsNames,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+}
+
+// WebsiteCertificate is the latest certificate seen for a website, with the leaf
+// fields copied out of the chain.
+type WebsiteCertificate struct {
+	Certificate
+	WebsiteID string        `json:"websiteId"`
+	Chain     []Certificate `json:"chain"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// DaysUntilExpiry rounds down, so a certificate expiring in a few hours has 0 days left.
+func (c WebsiteCertificate) DaysUntilExpiry(now time.Time) int {
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
+
+// State is warning once the certificate expires within thresholdDays.
+func (c WebsiteCertificate) State(thresholdDays int, now time.Time) string {
+	switch {
+	case !now.Before(c.NotAfter):
+		return CertificateExpired
+	case c.NotAfter.Before(now.AddDate(0, 0, thresholdDays)):
+		return CertificateWarning
+	default:
+		return CertificateValid
+	}
+}
+
+type CertificateStorage struct {
+	db *pgxpool.Pool
+}
+
+// UpsertCertificate stores the chain seen by a check, unless a later check was
+// stored already. The warning is reset when the certificate is renewed.
+func (s *CertificateStorage) UpsertCertificate(ctx context.Context, websiteID string, chain []Certificate, checkedAt time.Time) error {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO "website_certificate" (website_id, subject, issuer, dns_names, not_before, not_after, chain, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (website_id) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			issuer = EXCLUDED.issuer,
+			dns_names = EXCLUDED.dns_names,
+			not_before = EXCLUDED.not_before,
+			not_after = EXCLUDED.not_after,
+			chain = EXCLUDED.chain,
+			checked_at = EXCLUDED.checked_at,
+			warned_at = CASE
+				WHEN website_certificate.not_after = EXCLUDED.not_after THEN website_certificate.warned_at
+			END
+		WHERE website_certificate.checked_at < EXCLUDED.checked_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	leaf := chain[0]
+	dnsNames := leaf.DNSNames
+	if dnsNames == nil {
+		dnsNames = []string{}
+	}
+
+	_, err := s.db.Exec(ctx, query, websiteID, leaf.Subject, leaf.Issuer, dnsNames, leaf.NotBefore, leaf.NotAfter, chain, checkedAt)
+	return err
+}
+
+// WarnCertificate marks the certificate of a website as warned if it expires
+// within the threshold of the website and was not warned about yet. It returns
+// ErrNotFound when there is nothing to warn about.
+func (s *CertificateStorage) WarnCertificate(ctx context.Context, websiteID string) (*WebsiteCertificate, error) {
+	query := `
+		UPDATE "website_certificate" c
+		SET warned_at = NOW()
+		FROM "website" w
+		WHERE
+			c.website_id = $1
+			AND w.id = c.website_id
+			AND c.warned_at IS NULL
+			AND c.not_after < NOW() + make_interval(days => w.certificate_expiry_days)
+		RETURNING c.website_id, c.subject, c.issuer, c.dns_names, c.not_before, c.not_after, c.chain, c.checked_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cert, err := scanCertificate(s.db.QueryRow(ctx, query, websiteID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+func (s *CertificateStorage) GetCertificate(ctx context.Context, websiteID string) (*WebsiteCertificate, error) {
+	query := `
+		SELECT website_id, subject, issuer, dns_names, not_before, not_after, chain, checked_at
+		FROM "website_certificate"
+		WHERE website_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cert, err := scanCertificate(s.db.QueryRow(ctx, query, websiteID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+func scanCertificate(row pgx.Row) (*WebsiteCertificate, error) {
+	var c WebsiteCertificate
+
+	err := row.Scan(
+		&c.WebsiteID,
+		&c.Subject,
+		&c.Issuer,
+		&c.DNSNames,
+		&c.NotBefore,
+		&c.NotAfter,
+		&c.Chain,
+		&c.CheckedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
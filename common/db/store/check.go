@@ -0,0 +1,235 @@
+package store
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type CheckType string
+
+const (
+	CheckHTTP      CheckType = "http"
+	CheckTCP       CheckType = "tcp"
+	CheckDNS       CheckType = "dns"
+	CheckTLS       CheckType = "tls"
+	CheckContent   CheckType = "content"
+	CheckMultiStep CheckType = "multistep"
+	CheckHeartbeat CheckType = "heartbeat"
+)
+
+type AssertionType string
+
+const (
+	AssertContains AssertionType = "contains"
+	AssertRegex    AssertionType = "regex"
+	AssertJSONPath AssertionType = "json_path"
+)
+
+var (
+	DefaultTimeout  = time.Second * 2
+	MaxResponseBody = int64(1 << 20)
+	// Longest body snippet stored with the tick of a content check
+	MaxContentSnippet = 512
+)
+
+type StatusRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type Assertion struct {
+	Type  AssertionType `json:"type"`
+	Path  string        `json:"path,omitempty"`
+	Value string        `json:"value"`
+}
+
+// DNSSpec configures a dns check. Expected, when set, must be one of the answers.
+type DNSSpec struct {
+	RecordType string `json:"recordType"`
+	Expected   string `json:"expected,omitempty"`
+	Resolver   string `json:"resolver,omitempty"`
+}
+
+// TLSSpec configures a tls check. ServerName defaults to the host of the target.
+type TLSSpec struct {
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// ContentSpec configures a content check. The body is scoped to the text of the
+// elements matching Selector and then to the matches of Regex, when set, before
+// it is hashed and searched for keywords.
+type ContentSpec struct {
+	Selector  string   `json:"selector,omitempty"`
+	Regex     string   `json:"regex,omitempty"`
+	Required  []string `json:"required,omitempty"`
+	Forbidden []string `json:"forbidden,omitempty"`
+}
+
+type ExtractionSource string
+
+const (
+	ExtractJSONPath ExtractionSource = "json_path"
+	ExtractHeader   ExtractionSource = "header"
+	ExtractRegex    ExtractionSource = "regex"
+)
+
+// Extraction saves a value of a step response as a variable. Path is the json
+// path, the header name or a regex whose first group, if any, is the value.
+type Extraction struct {
+	Name string           `json:"name"`
+	From ExtractionSource `json:"from"`
+	Path string           `json:"path"`
+}
+
+// Step is a request of a multistep check. Variables extracted by earlier steps
+// are interpolated as {{name}} into its url, headers and body. A relative url is
+// resolved against the url of the website.
+type Step struct {
+	Name           string            `json:"name"`
+	Method         string            `json:"method,omitempty"`
+	Url            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	AcceptedStatus []StatusRange     `json:"acceptedStatus,omitempty"`
+	Assertions     []Assertion       `json:"assertions,omitempty"`
+	Extract        []Extraction      `json:"extract,omitempty"`
+}
+
+// Spec is the step as a single request check.
+func (s Step) Spec(timeoutMS int64) CheckSpec {
+	return CheckSpec{
+		Method:         s.Method,
+		Headers:        s.Headers,
+		Body:           s.Body,
+		TimeoutMS:      timeoutMS,
+		AcceptedStatus: s.AcceptedStatus,
+		Assertions:     s.Assertions,
+	}
+}
+
+// HeartbeatSpec configures a heartbeat monitor, which is down once no ping arrived
+// for its frequency plus the grace period.
+type HeartbeatSpec struct {
+	GraceSeconds int64 `json:"graceSeconds,omitempty"`
+}
+
+// CheckSpec describes how the worker should check a website.
+// The zero value behaves like the original HEAD check.
+type CheckSpec struct {
+	Method         string            `json:"method,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	TimeoutMS      int64             `json:"timeoutMs,omitempty"`
+	AcceptedStatus []StatusRange     `json:"acceptedStatus,omitempty"`
+	Assertions     []Assertion       `json:"assertions,omitempty"`
+	DNS            *DNSSpec          `json:"dns,omitempty"`
+	TLS            *TLSSpec          `json:"tls,omitempty"`
+	Content        *ContentSpec      `json:"content,omitempty"`
+	Steps          []Step            `json:"steps,omitempty"`
+	Heartbeat      *HeartbeatSpec    `json:"heartbeat,omitempty"`
+}
+
+type TCPResult struct {
+	RemoteAddr string `json:"remoteAddr"`
+}
+
+type DNSResult struct {
+	RecordType string   `json:"recordType"`
+	Answers    []string `json:"answers"`
+}
+
+type StepResult struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	StatusCode      int    `json:"statusCode,omitempty"`
+	ResponseTimeMS  int64  `json:"responseTime"`
+	FailedAssertion string `json:"failedAssertion,omitempty"`
+	Error           string `json:"error,omitempty"`
+	ErrorKind       string `json:"errorKind,omitempty"`
+}
+
+type HeartbeatResult struct {
+	Event    string `json:"event"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+}
+
+type TLSResult struct {
+	Version string `json:"version"`
+	Cipher  string `json:"cipher"`
+}
+
+// CheckResult holds the details of a check stored with its tick, set for the
+// type of the check only. Certificates is the peer chain of https and tls checks,
+// leaf first, kept in website_certificate rather than with the tick. Records is
+// the dns snapshot of the host of the website. Steps are the results of a
+// multistep check up to the first failing step.
+type CheckResult struct {
+	TCP          *TCPResult       `json:"tcp,omitempty"`
+	DNS          *DNSResult       `json:"dns,omitempty"`
+	TLS          *TLSResult       `json:"tls,omitempty"`
+	Certificates []Certificate    `json:"certificates,omitempty"`
+	Records      DNSRecords       `json:"records,omitempty"`
+	Steps        []StepResult     `json:"steps,omitempty"`
+	Heartbeat    *HeartbeatResult `json:"heartbeat,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+func (c CheckSpec) HttpMethod() string {
+	if c.Method != "" {
+		return c.Method
+	}
+
+	// A HEAD response has no body to assert on
+	if len(c.Assertions) > 0 {
+		return http.MethodGet
+	}
+
+	return http.MethodHead
+}
+
+func (c CheckSpec) Grace() time.Duration {
+	if c.Heartbeat == nil {
+		return 0
+	}
+
+	return time.Duration(c.Heartbeat.GraceSeconds) * time.Second
+}
+
+func (c CheckSpec) Timeout() time.Duration {
+	if c.TimeoutMS <= 0 {
+		return DefaultTimeout
+	}
+
+	return time.Duration(c.TimeoutMS) * time.Millisecond
+}
+
+// StatusFor maps a response status code to a website status.
+func (c CheckSpec) StatusFor(code int) WebsiteStatus {
+	if len(c.AcceptedStatus) == 0 {
+		switch {
+		case code >= 200 && code <= 403:
+			return Up
+		case code >= 500 && code <= 599:
+			return Down
+		default:
+			return Unknown
+		}
+	}
+
+	for _, r := range c.AcceptedStatus {
+		if code >= r.Min && code <= r.Max {
+			return Up
+		}
+	}
+
+	return Down
+}
+
+func (a Assertion) String() string {
+	if a.Path != "" {
+		return fmt.Sprintf("%s %s == %q", a.Type, a.Path, a.Value)
+	}
+
+	return fmt.Sprintf("%s %q", a.Type, a.Value)
+}
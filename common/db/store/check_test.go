@@ -0,0 +1,34 @@
+package store
+
+import "testing"
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted []StatusRange
+		code     int
+		want     WebsiteStatus
+	}{
+		{"ok", nil, 200, Up},
+		{"redirect", nil, 301, Up},
+		{"forbidden", nil, 403, Up},
+		{"not found", nil, 404, Unknown},
+		{"informational", nil, 101, Unknown},
+		{"server error", nil, 500, Down},
+		{"last server error", nil, 599, Down},
+		{"out of range", nil, 600, Unknown},
+		{"accepted", []StatusRange{{200, 299}, {404, 404}}, 404, Up},
+		{"accepted bound", []StatusRange{{200, 299}}, 299, Up},
+		{"not accepted", []StatusRange{{200, 299}}, 301, Down},
+		{"not accepted server error", []StatusRange{{200, 299}}, 503, Down},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckSpec{AcceptedStatus: tt.accepted}
+			if got := check.StatusFor(tt.code); got != tt.want {
+				t.Errorf("StatusFor(%d) = %s, want %s", tt.code, got, tt.want)
+			}
+		})
+	}
+}
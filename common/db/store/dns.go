@@ -0,0 +1,314 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SnapshotRecordTypes are the records resolved for the host of a website by the
+// periodic snapshots of the workers.
+var SnapshotRecordTypes = []string{"A", "AAAA", "CNAME", "NS"}
+
+// DNSRecords maps a record type to its answers, normalized and sorted.
+type DNSRecords map[string][]string
+
+// NewDNSRecords normalizes the answers so record sets compare regardless of order,
+// case or trailing dots. Types without answers are dropped.
+func NewDNSRecords(records map[string][]string) DNSRecords {
+	r := DNSRecords{}
+
+	for recordType, answers := range records {
+		var normalized []string
+		for _, a := range answers {
+			a = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(a)), ".")
+			if a != "" && !slices.Contains(normalized, a) {
+				normalized = append(normalized, a)
+			}
+		}
+
+		if len(normalized) > 0 {
+			slices.Sort(normalized)
+			r[strings.ToUpper(recordType)] = normalized
+		}
+	}
+
+	return r
+}
+
+func (r DNSRecords) Equal(o DNSRecords) bool {
+	if len(r) != len(o) {
+		return false
+	}
+
+	for recordType, answers := range r {
+		if !slices.Equal(answers, o[recordType]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Drift describes every pinned record type whose answers differ from the pins.
+func (r DNSRecords) Drift(pins DNSRecords) []string {
+	var drift []string = []string{}
+
+	for _, recordType := range SnapshotRecordTypes {
+		pinned, ok := pins[recordType]
+		if !ok || slices.Equal(pinned, r[recordType]) {
+			continue
+		}
+
+		drift = append(drift, fmt.Sprintf("%s expected [%s], got [%s]", recordType, strings.Join(pinned, ", "), strings.Join(r[recordType], ", ")))
+	}
+
+	return drift
+}
+
+type DNSSnapshot struct {
+	ID         string     `json:"id"`
+	WebsiteID  string     `json:"websiteId"`
+	RegionID   string     `json:"regionId"`
+	Region     string     `json:"region"`
+	Records    DNSRecords `json:"records"`
+	Drift      []string   `json:"drift"`
+	ObservedAt time.Time  `json:"observedAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+}
+
+type DNSStorage struct {
+	db *pgxpool.Pool
+}
+
+// RecordSnapshot compares the records resolved in a region with the latest snapshot
+// of that region and stores a new one when the records or their drift from the pins
+// changed. It returns the new snapshot and the one it replaced, both nil when nothing
+// changed. Snapshots older than the latest one are ignored.
+func (s *DNSStorage) RecordSnapshot(ctx context.Context, websiteID string, regionID string, records DNSRecords, observedAt time.Time) (*DNSSnapshot, *DNSSnapshot, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	// Serialize db workers recording the same website and region
+	lockQuery := `SELECT pg_advisory_xact_lock(hashtext($1 || $2))`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.Exec(queryCtx, lockQuery, websiteID, regionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pinsQuery := `
+		SELECT dns_pins
+		FROM "website"
+		WHERE id = $1
+	`
+
+	queryCtx, cancel = context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var pins DNSRecords
+	err = tx.QueryRow(queryCtx, pinsQuery, websiteID).Scan(&pins)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	latestQuery := `
+		SELECT id, website_id, region_id, records, drift, observed_at, last_seen_at
+		FROM "dns_snapshot"
+		WHERE website_id = $1 AND region_id = $2
+		ORDER BY observed_at DESC
+		LIMIT 1
+	`
+
+	queryCtx, cancel = context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var previous *DNSSnapshot
+
+	latest, err := scanSnapshot(tx.QueryRow(queryCtx, latestQuery, websiteID, regionID))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return nil, nil, err
+	default:
+		previous = latest
+	}
+
+	drift := records.Drift(pins)
+
+	if previous != nil {
+		if !observedAt.After(previous.LastSeenAt) {
+			return nil, nil, nil
+		}
+
+		if previous.Records.Equal(records) && slices.Equal(previous.Drift, drift) {
+			seenQuery := `
+				UPDATE "dns_snapshot"
+				SET last_seen_at = $1
+				WHERE id = $2
+			`
+
+			queryCtx, cancel = context.WithTimeout(ctx, QueryTimeoutDuration)
+			defer cancel()
+
+			if _, err = tx.Exec(queryCtx, seenQuery, observedAt, previous.ID); err != nil {
+				return nil, nil, err
+			}
+
+			return nil, nil, tx.Commit(ctx)
+		}
+	}
+
+	insertQuery := `
+		INSERT INTO "dns_snapshot" (website_id, region_id, records, drift, observed_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`
+
+	snapshot := &DNSSnapshot{
+		WebsiteID:  websiteID,
+		RegionID:   regionID,
+		Records:    records,
+		Drift:      drift,
+		ObservedAt: observedAt,
+		LastSeenAt: observedAt,
+	}
+
+	queryCtx, cancel = context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = tx.QueryRow(queryCtx, insertQuery, websiteID, regionID, records, drift, observedAt).Scan(&snapshot.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return snapshot, previous, nil
+}
+
+// GetSnapshots returns the latest snapshots of a website across its regions.
+func (s *DNSStorage) GetSnapshots(ctx context.Context, websiteID string, limit int) ([]DNSSnapshot, error) {
+	query := `
+		SELECT s.id, s.website_id, s.region_id, s.records, s.drift, s.observed_at, s.last_seen_at, r.name
+		FROM "dns_snapshot" s
+		JOIN "region" r ON r.id = s.region_id
+		WHERE s.website_id = $1
+		ORDER BY s.observed_at DESC
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []DNSSnapshot = []DNSSnapshot{}
+
+	for rows.Next() {
+		var snapshot DNSSnapshot
+
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.WebsiteID,
+			&snapshot.RegionID,
+			&snapshot.Records,
+			&snapshot.Drift,
+			&snapshot.ObservedAt,
+			&snapshot.LastSeenAt,
+			&snapshot.Region,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+func (s *DNSStorage) GetPins(ctx context.Context, websiteID string) (DNSRecords, error) {
+	query := `
+		SELECT dns_pins
+		FROM "website"
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var pins DNSRecords
+	err := s.db.QueryRow(ctx, query, websiteID).Scan(&pins)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return pins, nil
+}
+
+func (s *DNSStorage) UpdatePins(ctx context.Context, websiteID string, pins DNSRecords) error {
+	query := `
+		UPDATE "website"
+		SET dns_pins = $1
+		WHERE id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, pins, websiteID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanSnapshot(row pgx.Row) (*DNSSnapshot, error) {
+	var snapshot DNSSnapshot
+
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.WebsiteID,
+		&snapshot.RegionID,
+		&snapshot.Records,
+		&snapshot.Drift,
+		&snapshot.ObservedAt,
+		&snapshot.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
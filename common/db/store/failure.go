@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of check failures. Failures without a kind, recorded before kinds
+// existed, are unknown.
+const (
+	ErrorTimeout           = "timeout"
+	ErrorDNSNotFound       = "dns_nxdomain"
+	ErrorDNS               = "dns"
+	ErrorConnectionRefused = "connection_refused"
+	ErrorConnectionReset   = "connection_reset"
+	ErrorConnection        = "connection"
+	ErrorTLS               = "tls"
+	ErrorHTTPStatus        = "http_status"
+	ErrorAssertion         = "assertion"
+	ErrorHeartbeat         = "heartbeat"
+	ErrorUnknown           = "unknown"
+)
+
+// Longest error message stored with a tick
+var MaxErrorMessage = 512
+
+// FailureReason counts the failed ticks of a website sharing a kind and status
+// code. Regions are the regions the failure was seen from.
+type FailureReason struct {
+	ErrorKind      string    `json:"errorKind"`
+	HTTPStatusCode *int      `json:"httpStatusCode,omitempty"`
+	Count          int64     `json:"count"`
+	Regions        []string  `json:"regions"`
+	LastMessage    *string   `json:"lastMessage,omitempty"`
+	FirstSeen      time.Time `json:"firstSeen"`
+	LastSeen       time.Time `json:"lastSeen"`
+}
+
+type FailureSummary struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Checks   int64           `json:"checks"`
+	Failures int64           `json:"failures"`
+	Reasons  []FailureReason `json:"reasons"`
+}
+
+// GetFailures aggregates the failed ticks of a website between from and to by
+// reason, most frequent first. An empty region covers every region.
+func (s *WebsiteTickStorage) GetFailures(ctx context.Context, websiteID string, region string, from time.Time, to time.Time) (*FailureSummary, error) {
+	totalQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE wt.status = 'down')
+		FROM "website_tick" wt
+		JOIN "region" r ON wt.region_id = r.id
+		WHERE
+			wt.website_id = $1
+			AND wt.time BETWEEN $2 AND $3
+			AND ($4 = '' OR r.name = $4)
+	`
+
+	summary := &FailureSummary{
+		From:    from,
+		To:      to,
+		Reasons: []FailureReason{},
+	}
+
+	totalCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRow(totalCtx, totalQuery, websiteID, from, to, region).Scan(&summary.Checks, &summary.Failures)
+	if err != nil {
+		return nil, err
+	}
+
+	reasonsQuery := `
+		SELECT
+			COALESCE(wt.error_kind, 'unknown') AS kind,
+			wt.http_status_code,
+			COUNT(*),
+			array_agg(DISTINCT r.name ORDER BY r.name),
+			(array_agg(wt.error_message ORDER BY wt.time DESC) FILTER (WHERE wt.error_message IS NOT NULL))[1],
+			MIN(wt.time),
+			MAX(wt.time)
+		FROM "website_tick" wt
+		JOIN "region" r ON wt.region_id = r.id
+		WHERE
+			wt.website_id = $1
+			AND wt.time BETWEEN $2 AND $3
+			AND ($4 = '' OR r.name = $4)
+			AND wt.status = 'down'
+		GROUP BY kind, wt.http_status_code
+		ORDER BY COUNT(*) DESC, kind
+	`
+
+	reasonsCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(reasonsCtx, reasonsQuery, websiteID, from, to, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r FailureReason
+
+		err := rows.Scan(
+			&r.ErrorKind,
+			&r.HTTPStatusCode,
+			&r.Count,
+			&r.Regions,
+			&r.LastMessage,
+			&r.FirstSeen,
+			&r.LastSeen,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Reasons = append(summary.Reasons, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	HeartbeatStart   = "start"
+	HeartbeatSuccess = "success"
+	HeartbeatFail    = "fail"
+	HeartbeatMissed  = "missed"
+)
+
+// Heartbeat is the state of a heartbeat monitor. LastAt is nil until the first
+// success or fail ping, ResumedAt until the monitor is first resumed.
+type Heartbeat struct {
+	WebsiteID string
+	Regions   []Region
+	LastAt    *time.Time
+	StartedAt *time.Time
+	CreatedAt time.Time
+	Paused    bool
+	ResumedAt *time.Time
+}
+
+// ExpectedSince returns the time pings are expected from: the last ping, the
+// creation without one, or the resume when the monitor was resumed since.
+func (h Heartbeat) ExpectedSince() time.Time {
+	since := h.CreatedAt
+	if h.LastAt != nil {
+		since = *h.LastAt
+	}
+
+	if h.ResumedAt != nil && h.ResumedAt.After(since) {
+		since = *h.ResumedAt
+	}
+
+	return since
+}
+
+// Ticks records a ping, or a missed one, as a tick in every region of the monitor
+// so uptime and incidents work as for other checks.
+func (h Heartbeat) Ticks(status WebsiteStatus, at time.Time, durationMS int64, result HeartbeatResult) []WebsiteTick {
+	var ticks []WebsiteTick
+
+	var errorKind, errorMessage *string
+	if status == Down {
+		kind, message := ErrorHeartbeat, result.Event
+		if result.ExitCode != nil {
+			message = fmt.Sprintf("%s with exit code %d", result.Event, *result.ExitCode)
+		}
+		errorKind, errorMessage = &kind, &message
+	}
+
+	for _, r := range h.Regions {
+		ticks = append(ticks, WebsiteTick{
+			Time:           at,
+			CheckedAt:      &at,
+			ResponseTimeMS: &durationMS,
+			Status:         status.String(),
+			RegionID:       r.ID,
+			WebsiteID:      &h.WebsiteID,
+			Result:         &CheckResult{Heartbeat: &result},
+			ErrorKind:      errorKind,
+			ErrorMessage:   errorMessage,
+		})
+	}
+
+	return ticks
+}
+
+type HeartbeatStorage struct {
+	db *pgxpool.Pool
+}
+
+func (s *HeartbeatStorage) GetHeartbeat(ctx context.Context, websiteID string) (*Heartbeat, error) {
+	return s.getHeartbeat(ctx, "w.id = $1", websiteID)
+}
+
+func (s *HeartbeatStorage) GetHeartbeatByToken(ctx context.Context, tokenHash string) (*Heartbeat, error) {
+	return s.getHeartbeat(ctx, "w.heartbeat_token_hash = $1", tokenHash)
+}
+
+func (s *HeartbeatStorage) getHeartbeat(ctx context.Context, where string, arg string) (*Heartbeat, error) {
+	query := `
+		SELECT
+			w.id,
+			w.heartbeat_at,
+			w.heartbeat_started_at,
+			w.created_at,
+			w.paused_at IS NOT NULL,
+			w.resumed_at,
+			r.id,
+			r.name
+		FROM
+			website w
+		JOIN
+			website_region wr ON w.id = wr.website_id
+		JOIN
+			region r ON wr.region_id = r.id
+		WHERE
+			w.check_type = 'heartbeat' AND w.deleted_at IS NULL AND ` + where
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	h := &Heartbeat{}
+
+	for rows.Next() {
+		var region Region
+
+		err = rows.Scan(
+			&h.WebsiteID,
+			&h.LastAt,
+			&h.StartedAt,
+			&h.CreatedAt,
+			&h.Paused,
+			&h.ResumedAt,
+			&region.ID,
+			&region.Name,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		h.Regions = append(h.Regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if h.WebsiteID == "" {
+		return nil, ErrNotFound
+	}
+
+	return h, nil
+}
+
+// RecordPing stores a ping of a heartbeat monitor. A start ping marks a run in
+// progress, other pings finish it and return when it started, if it did.
+func (s *HeartbeatStorage) RecordPing(ctx context.Context, websiteID string, event string, at time.Time) (*time.Time, error) {
+	query := `
+		WITH previous AS (
+			SELECT heartbeat_started_at
+			FROM website
+			WHERE id = $1
+			FOR UPDATE
+		)
+		UPDATE website
+		SET heartbeat_at = $2, heartbeat_started_at = NULL
+		WHERE id = $1
+		RETURNING (SELECT heartbeat_started_at FROM previous)
+	`
+	if event == HeartbeatStart {
+		query = `
+			UPDATE website
+			SET heartbeat_started_at = $2
+			WHERE id = $1
+			RETURNING heartbeat_started_at
+		`
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var startedAt *time.Time
+	err := s.db.QueryRow(ctx, query, websiteID, at).Scan(&startedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return startedAt, nil
+}
+
+// SetToken replaces the ingestion token of a heartbeat monitor.
+func (s *HeartbeatStorage) SetToken(ctx context.Context, websiteID string, tokenHash string) error {
+	query := `
+		UPDATE website
+		SET heartbeat_token_hash = $1
+		WHERE id = $2 AND check_type = 'heartbeat'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, tokenHash, websiteID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
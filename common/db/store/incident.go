@@ -0,0 +1,319 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	IncidentOpen         = "open"
+	IncidentAcknowledged = "acknowledged"
+	IncidentResolved     = "resolved"
+)
+
+type Incident struct {
+	ID              string     `json:"id"`
+	WebsiteID       string     `json:"websiteId"`
+	Status          string     `json:"status"`
+	AffectedRegions []string   `json:"affectedRegions"`
+	StartedAt       time.Time  `json:"startedAt"`
+	AcknowledgedAt  *time.Time `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy  *string    `json:"acknowledgedBy,omitempty"`
+	ResolvedAt      *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy      *string    `json:"resolvedBy,omitempty"`
+	DurationSeconds int64      `json:"durationSeconds"`
+}
+
+type IncidentStorage struct {
+	db *pgxpool.Pool
+}
+
+const incidentColumns = `
+	id, website_id, status, affected_regions, started_at,
+	acknowledged_at, acknowledged_by, resolved_at, resolved_by
+`
+
+func scanIncident(row pgx.Row) (*Incident, error) {
+	var i Incident
+
+	err := row.Scan(
+		&i.ID,
+		&i.WebsiteID,
+		&i.Status,
+		&i.AffectedRegions,
+		&i.StartedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if i.ResolvedAt != nil {
+		end = *i.ResolvedAt
+	}
+	i.DurationSeconds = int64(end.Sub(i.StartedAt).Seconds())
+
+	return &i, nil
+}
+
+// GetOpenIncidents returns every unresolved incident, used to seed the tracker on startup.
+func (s *IncidentStorage) GetOpenIncidents(ctx context.Context) ([]Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM "incident"
+		WHERE status <> 'resolved'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []Incident = []Incident{}
+
+	for rows.Next() {
+		i, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		incidents = append(incidents, *i)
+	}
+
+	return incidents, nil
+}
+
+// GetLatestIncident returns the last incident opened for a website.
+func (s *IncidentStorage) GetLatestIncident(ctx context.Context, websiteID string) (*Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM "incident"
+		WHERE website_id = $1
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, websiteID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// UpsertOpenIncident opens an incident for the website or updates the affected
+// regions of the one already open. created is true when a new incident was opened.
+func (s *IncidentStorage) UpsertOpenIncident(ctx context.Context, websiteID string, regionIDs []string, startedAt time.Time) (incident *Incident, created bool, err error) {
+	query := `
+		INSERT INTO "incident" (website_id, affected_regions, started_at)
+		VALUES ($1, ARRAY(SELECT name FROM "region" WHERE id = ANY($2::uuid[]) ORDER BY name), $3)
+		ON CONFLICT (website_id) WHERE status <> 'resolved'
+		DO UPDATE SET
+			affected_regions = ARRAY(
+				SELECT DISTINCT unnest(incident.affected_regions || EXCLUDED.affected_regions) ORDER BY 1
+			)
+		RETURNING ` + incidentColumns + `, (xmax = 0)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var i Incident
+
+	err = s.db.QueryRow(ctx, query, websiteID, regionIDs, startedAt).Scan(
+		&i.ID,
+		&i.WebsiteID,
+		&i.Status,
+		&i.AffectedRegions,
+		&i.StartedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+		&created,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	i.DurationSeconds = int64(time.Since(i.StartedAt).Seconds())
+
+	return &i, created, nil
+}
+
+// ResolveOpenIncident closes the unresolved incident of a website, if any.
+func (s *IncidentStorage) ResolveOpenIncident(ctx context.Context, websiteID string, resolvedAt time.Time) (*Incident, error) {
+	query := `
+		UPDATE "incident"
+		SET status = 'resolved', resolved_at = $2
+		WHERE website_id = $1 AND status <> 'resolved'
+		RETURNING ` + incidentColumns
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, websiteID, resolvedAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (s *IncidentStorage) GetIncidents(ctx context.Context, websiteID string, limit int) ([]Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM "incident"
+		WHERE website_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []Incident = []Incident{}
+
+	for rows.Next() {
+		i, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		incidents = append(incidents, *i)
+	}
+
+	return incidents, nil
+}
+
+// GetRecentIncidents lists the incidents of several websites started after since.
+func (s *IncidentStorage) GetRecentIncidents(ctx context.Context, websiteIDs []string, since time.Time, limit int) ([]Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM "incident"
+		WHERE website_id = ANY($1::uuid[]) AND started_at >= $2
+		ORDER BY started_at DESC
+		LIMIT $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteIDs, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []Incident = []Incident{}
+
+	for rows.Next() {
+		i, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		incidents = append(incidents, *i)
+	}
+
+	return incidents, nil
+}
+
+func (s *IncidentStorage) GetIncidentById(ctx context.Context, id string, websiteID string) (*Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM "incident"
+		WHERE id = $1 AND website_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, id, websiteID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (s *IncidentStorage) AcknowledgeIncident(ctx context.Context, id string, websiteID string, userId string) (*Incident, error) {
+	query := `
+		UPDATE "incident"
+		SET status = 'acknowledged', acknowledged_at = NOW(), acknowledged_by = $3
+		WHERE id = $1 AND website_id = $2 AND status = 'open'
+		RETURNING ` + incidentColumns
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, id, websiteID, userId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIncidentNotOpen
+		}
+
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (s *IncidentStorage) ResolveIncident(ctx context.Context, id string, websiteID string, userId string) (*Incident, error) {
+	query := `
+		UPDATE "incident"
+		SET status = 'resolved', resolved_at = NOW(), resolved_by = $3
+		WHERE id = $1 AND website_id = $2 AND status <> 'resolved'
+		RETURNING ` + incidentColumns
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, id, websiteID, userId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIncidentNotOpen
+		}
+
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+var (
+	ErrIncidentNotOpen = errors.New("incident does not exist or is already closed")
+)
@@ -0,0 +1,368 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/robfig/cron/v3"
+)
+
+// MaintenanceWindow suppresses the downtime of websites while it is active. A
+// recurring window starts at every occurrence of its cron expression from StartsAt
+// until Until, a one-off window at StartsAt. Ticks checked during a window are
+// stored as maintenance.
+type MaintenanceWindow struct {
+	ID              string     `json:"id"`
+	OrganizationID  string     `json:"organizationId"`
+	Name            string     `json:"name"`
+	StartsAt        time.Time  `json:"startsAt"`
+	DurationSeconds int64      `json:"durationSeconds"`
+	Recurrence      *string    `json:"recurrence,omitempty"`
+	Until           *time.Time `json:"until,omitempty"`
+	Timezone        string     `json:"timezone"`
+	SkipChecks      bool       `json:"skipChecks"`
+	AllWebsites     bool       `json:"allWebsites"`
+	WebsiteIDs      []string   `json:"websiteIds"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// ParseRecurrence parses the standard 5 field cron expression of a recurring
+// window, or a descriptor like @daily.
+func ParseRecurrence(expr string) (cron.Schedule, error) {
+	return cron.ParseStandard(expr)
+}
+
+func (w MaintenanceWindow) Duration() time.Duration {
+	return time.Duration(w.DurationSeconds) * time.Second
+}
+
+// Active reports whether an occurrence of the window covers at. Windows with an
+// invalid recurrence or timezone are never active.
+func (w MaintenanceWindow) Active(at time.Time) bool {
+	if at.Before(w.StartsAt) {
+		return false
+	}
+
+	if w.Recurrence == nil {
+		return at.Before(w.StartsAt.Add(w.Duration()))
+	}
+
+	start := w.occurrenceAfter(at.Add(-w.Duration()))
+	return start != nil && !start.After(at)
+}
+
+// Next returns the start of the first occurrence after t, nil once the window is
+// over.
+func (w MaintenanceWindow) Next(t time.Time) *time.Time {
+	if w.Recurrence == nil {
+		if !w.StartsAt.After(t) {
+			return nil
+		}
+		return &w.StartsAt
+	}
+
+	return w.occurrenceAfter(t)
+}
+
+// occurrenceAfter returns the first occurrence of a recurring window after t that
+// starts no earlier than StartsAt and no later than Until.
+func (w MaintenanceWindow) occurrenceAfter(t time.Time) *time.Time {
+	schedule, err := ParseRecurrence(*w.Recurrence)
+	if err != nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil
+	}
+
+	// Next is strictly after its argument
+	if first := w.StartsAt.Add(-time.Second); t.Before(first) {
+		t = first
+	}
+
+	next := schedule.Next(t.In(loc))
+	if next.IsZero() || (w.Until != nil && next.After(*w.Until)) {
+		return nil
+	}
+
+	return &next
+}
+
+type MaintenanceStorage struct {
+	db *pgxpool.Pool
+}
+
+const maintenanceColumns = `
+	mw.id, mw.organization_id, mw.name, mw.starts_at, mw.duration_seconds, mw.recurrence,
+	mw.until, mw.timezone, mw.skip_checks, mw.all_websites, mw.created_at,
+	ARRAY(SELECT website_id::text FROM maintenance_window_website WHERE window_id = mw.id ORDER BY website_id)
+`
+
+func scanMaintenanceWindow(row pgx.Row, extra ...any) (*MaintenanceWindow, error) {
+	var w MaintenanceWindow
+
+	targets := append([]any{
+		&w.ID,
+		&w.OrganizationID,
+		&w.Name,
+		&w.StartsAt,
+		&w.DurationSeconds,
+		&w.Recurrence,
+		&w.Until,
+		&w.Timezone,
+		&w.SkipChecks,
+		&w.AllWebsites,
+		&w.CreatedAt,
+		&w.WebsiteIDs,
+	}, extra...)
+
+	if err := row.Scan(targets...); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (s *MaintenanceStorage) CreateWindow(ctx context.Context, w MaintenanceWindow) (*string, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO "maintenance_window" (organization_id, name, starts_at, duration_seconds, recurrence, until, timezone, skip_checks, all_websites)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = tx.QueryRow(queryCtx, query, w.OrganizationID, w.Name, w.StartsAt, w.DurationSeconds, w.Recurrence, w.Until, w.Timezone, w.SkipChecks, w.AllWebsites).Scan(&w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setWindowWebsites(ctx, tx, w); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &w.ID, nil
+}
+
+func (s *MaintenanceStorage) UpdateWindow(ctx context.Context, w MaintenanceWindow) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE "maintenance_window"
+		SET name = $1, starts_at = $2, duration_seconds = $3, recurrence = $4, until = $5,
+			timezone = $6, skip_checks = $7, all_websites = $8
+		WHERE id = $9 AND organization_id = $10
+	`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.Exec(queryCtx, query, w.Name, w.StartsAt, w.DurationSeconds, w.Recurrence, w.Until, w.Timezone, w.SkipChecks, w.AllWebsites, w.ID, w.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := setWindowWebsites(ctx, tx, w); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setWindowWebsites replaces the websites of a window. Every website must belong to
+// the organization of the window.
+func setWindowWebsites(ctx context.Context, tx pgx.Tx, w MaintenanceWindow) error {
+	deleteQuery := `
+		DELETE FROM "maintenance_window_website"
+		WHERE window_id = $1
+	`
+
+	deleteCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.Exec(deleteCtx, deleteQuery, w.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(w.WebsiteIDs) == 0 {
+		return nil
+	}
+
+	insertQuery := `
+		INSERT INTO "maintenance_window_website" (window_id, website_id)
+		SELECT $1, id
+		FROM "website"
+		WHERE id = ANY($2::uuid[]) AND organization_id = $3 AND deleted_at IS NULL
+	`
+
+	insertCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.Exec(insertCtx, insertQuery, w.ID, w.WebsiteIDs, w.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() != int64(len(w.WebsiteIDs)) {
+		return ErrWebsiteNotFound
+	}
+
+	return nil
+}
+
+func (s *MaintenanceStorage) GetWindows(ctx context.Context, organizationID string) ([]MaintenanceWindow, error) {
+	query := `
+		SELECT ` + maintenanceColumns + `
+		FROM "maintenance_window" mw
+		WHERE mw.organization_id = $1
+		ORDER BY mw.starts_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []MaintenanceWindow = []MaintenanceWindow{}
+
+	for rows.Next() {
+		w, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, *w)
+	}
+
+	return windows, rows.Err()
+}
+
+func (s *MaintenanceStorage) GetWindow(ctx context.Context, id string, organizationID string) (*MaintenanceWindow, error) {
+	query := `
+		SELECT ` + maintenanceColumns + `
+		FROM "maintenance_window" mw
+		WHERE mw.id = $1 AND mw.organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	w, err := scanMaintenanceWindow(s.db.QueryRow(ctx, query, id, organizationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (s *MaintenanceStorage) DeleteWindow(ctx context.Context, id string, organizationID string) error {
+	query := `
+		DELETE FROM "maintenance_window"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetWebsiteWindows returns the windows of every website that may still be active
+// after since, by website id.
+func (s *MaintenanceStorage) GetWebsiteWindows(ctx context.Context, since time.Time) (map[string][]MaintenanceWindow, error) {
+	query := `
+		SELECT ` + maintenanceColumns + `, w.id
+		FROM "maintenance_window" mw
+		JOIN "website" w ON
+			(mw.all_websites AND w.organization_id = mw.organization_id)
+			OR w.id IN (SELECT website_id FROM maintenance_window_website WHERE window_id = mw.id)
+		WHERE
+			w.deleted_at IS NULL
+			AND CASE
+				WHEN mw.recurrence IS NULL THEN mw.starts_at + mw.duration_seconds * INTERVAL '1 second' > $1
+				ELSE mw.until IS NULL OR mw.until + mw.duration_seconds * INTERVAL '1 second' > $1
+			END
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := map[string][]MaintenanceWindow{}
+
+	for rows.Next() {
+		var websiteID string
+
+		w, err := scanMaintenanceWindow(rows, &websiteID)
+		if err != nil {
+			return nil, err
+		}
+
+		windows[websiteID] = append(windows[websiteID], *w)
+	}
+
+	return windows, rows.Err()
+}
+
+// InMaintenance reports whether any of the windows is active at t.
+func InMaintenance(windows []MaintenanceWindow, t time.Time) bool {
+	for _, w := range windows {
+		if w.Active(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	ErrWebsiteNotFound = errors.New("website not found in the organization")
+)
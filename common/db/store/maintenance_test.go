@@ -0,0 +1,75 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowActive(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	recurrence := func(expr string) *string { return &expr }
+	until := at("2026-02-01T00:00:00Z")
+
+	oneOff := MaintenanceWindow{StartsAt: at("2026-01-15T10:00:00Z"), DurationSeconds: 3600, Timezone: "UTC"}
+	// 02:00 in New York is 07:00 UTC in winter and 06:00 UTC in summer
+	nightly := MaintenanceWindow{
+		StartsAt:        at("2026-01-01T00:00:00Z"),
+		DurationSeconds: 1800,
+		Recurrence:      recurrence("0 2 * * *"),
+		Timezone:        "America/New_York",
+	}
+	overMidnight := MaintenanceWindow{
+		StartsAt:        at("2026-01-01T00:00:00Z"),
+		DurationSeconds: 2 * 3600,
+		Recurrence:      recurrence("0 23 * * *"),
+		Timezone:        "UTC",
+	}
+	lateStart := nightly
+	lateStart.StartsAt = at("2026-01-15T12:00:00Z")
+	ending := nightly
+	ending.Until = &until
+	badTimezone := nightly
+	badTimezone.Timezone = "Mars/Olympus"
+	badRecurrence := nightly
+	badRecurrence.Recurrence = recurrence("every night")
+
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		at     string
+		want   bool
+	}{
+		{"before a one-off window", oneOff, "2026-01-15T09:59:59Z", false},
+		{"start of a one-off window", oneOff, "2026-01-15T10:00:00Z", true},
+		{"last second of a one-off window", oneOff, "2026-01-15T10:59:59Z", true},
+		{"end of a one-off window", oneOff, "2026-01-15T11:00:00Z", false},
+		{"winter occurrence", nightly, "2026-01-20T07:00:00Z", true},
+		{"winter occurrence in utc time", nightly, "2026-01-20T02:10:00Z", false},
+		{"winter occurrence before daylight saving", nightly, "2026-01-20T06:10:00Z", false},
+		{"summer occurrence", nightly, "2026-03-10T06:10:00Z", true},
+		{"last second of a summer occurrence", nightly, "2026-03-10T06:29:59Z", true},
+		{"end of a summer occurrence", nightly, "2026-03-10T06:30:00Z", false},
+		{"occurrence over midnight", overMidnight, "2026-01-20T00:30:00Z", true},
+		{"after an occurrence over midnight", overMidnight, "2026-01-20T01:00:00Z", false},
+		{"occurrence before the start", lateStart, "2026-01-15T07:10:00Z", false},
+		{"first occurrence after the start", lateStart, "2026-01-16T07:10:00Z", true},
+		{"occurrence before until", ending, "2026-01-31T07:10:00Z", true},
+		{"occurrence after until", ending, "2026-02-02T07:10:00Z", false},
+		{"invalid timezone", badTimezone, "2026-01-20T07:10:00Z", false},
+		{"invalid recurrence", badRecurrence, "2026-01-20T07:10:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Active(at(tt.at)); got != tt.want {
+				t.Errorf("Active(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
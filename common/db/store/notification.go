@@ -0,0 +1,454 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookConfig struct {
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Secret  string            `json:"secret,omitempty"`
+}
+
+type EmailConfig struct {
+	To []string `json:"to"`
+}
+
+// ChatConfig is shared by Slack and Discord incoming webhooks.
+type ChatConfig struct {
+	Url string `json:"url"`
+}
+
+type NotificationChannel struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"userId"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Config    json.RawMessage `json:"config"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Redacted is shown in place of the secrets of a channel.
+const Redacted = "********"
+
+// Redacted returns the channel without the secrets of its config, for members who
+// can't edit it. Chat webhook urls are credentials themselves, only their host is
+// kept.
+func (ch NotificationChannel) Redacted() NotificationChannel {
+	var cfg any
+
+	switch ch.Type {
+	case ChannelWebhook:
+		var webhook WebhookConfig
+		if err := json.Unmarshal(ch.Config, &webhook); err != nil {
+			return ch
+		}
+
+		webhook.Url = redactUrl(webhook.Url, false)
+		for k := range webhook.Headers {
+			webhook.Headers[k] = Redacted
+		}
+		if webhook.Secret != "" {
+			webhook.Secret = Redacted
+		}
+		cfg = webhook
+	case ChannelSlack, ChannelDiscord:
+		var chat ChatConfig
+		if err := json.Unmarshal(ch.Config, &chat); err != nil {
+			return ch
+		}
+
+		chat.Url = redactUrl(chat.Url, true)
+		cfg = chat
+	default:
+		return ch
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return ch
+	}
+
+	ch.Config = encoded
+	return ch
+}
+
+// redactUrl drops the credentials of an url and hides its query, and its path with
+// path.
+func redactUrl(raw string, path bool) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Redacted
+	}
+
+	u.User = nil
+	if u.RawQuery != "" {
+		u.RawQuery = Redacted
+	}
+	if path && u.Path != "" {
+		u.Path = "/" + Redacted
+		u.RawPath = u.Path
+	}
+
+	return u.String()
+}
+
+// Ranges outside the private, loopback and link-local ones that never reach the
+// public internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// PublicAddress reports whether notifications may be sent to ip. Private, loopback,
+// link-local and other internal addresses are refused, so channels can't reach the
+// network Echo runs in.
+func PublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type NotificationDelivery struct {
+	ID          string     `json:"id"`
+	ChannelID   string     `json:"channelId"`
+	WebsiteID   string     `json:"websiteId"`
+	IncidentID  *string    `json:"incidentId,omitempty"`
+	Event       string     `json:"event"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+}
+
+type NotificationStorage struct {
+	db *pgxpool.Pool
+}
+
+func (s *NotificationStorage) CreateChannel(ctx context.Context, ch NotificationChannel, userId string, organizationId string) (*string, error) {
+	query := `
+		INSERT INTO "notification_channel" (user_id, organization_id, name, type, config)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, query, userId, organizationId, ch.Name, ch.Type, ch.Config).Scan(&ch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ch.ID, nil
+}
+
+func (s *NotificationStorage) GetChannels(ctx context.Context, organizationId string) ([]NotificationChannel, error) {
+	query := `
+		SELECT id, user_id, name, type, config, created_at
+		FROM "notification_channel"
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChannels(rows)
+}
+
+func (s *NotificationStorage) GetChannelById(ctx context.Context, id string, organizationId string) (*NotificationChannel, error) {
+	query := `
+		SELECT id, user_id, name, type, config, created_at
+		FROM "notification_channel"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var ch NotificationChannel
+	err := s.db.QueryRow(ctx, query, id, organizationId).Scan(
+		&ch.ID,
+		&ch.UserID,
+		&ch.Name,
+		&ch.Type,
+		&ch.Config,
+		&ch.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &ch, nil
+}
+
+func (s *NotificationStorage) UpdateChannel(ctx context.Context, ch NotificationChannel, organizationId string) error {
+	query := `
+		UPDATE "notification_channel"
+		SET name = $1, type = $2, config = $3
+		WHERE id = $4 AND organization_id = $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, ch.Name, ch.Type, ch.Config, ch.ID, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *NotificationStorage) DeleteChannel(ctx context.Context, id string, organizationId string) error {
+	query := `
+		DELETE FROM "notification_channel"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *NotificationStorage) AttachChannel(ctx context.Context, websiteId string, channelId string) error {
+	query := `
+		INSERT INTO "website_notification_channel" (website_id, channel_id)
+		VALUES ($1, $2)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, websiteId, channelId)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" {
+			return ErrChannelAlreadyAttached
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *NotificationStorage) DetachChannel(ctx context.Context, websiteId string, channelId string) error {
+	query := `
+		DELETE FROM "website_notification_channel"
+		WHERE website_id = $1 AND channel_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, websiteId, channelId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *NotificationStorage) GetWebsiteChannels(ctx context.Context, websiteId string) ([]NotificationChannel, error) {
+	query := `
+		SELECT nc.id, nc.user_id, nc.name, nc.type, nc.config, nc.created_at
+		FROM "notification_channel" nc
+		JOIN "website_notification_channel" wnc ON nc.id = wnc.channel_id
+		WHERE wnc.website_id = $1
+		ORDER BY nc.created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChannels(rows)
+}
+
+func scanChannels(rows pgx.Rows) ([]NotificationChannel, error) {
+	var channels []NotificationChannel = []NotificationChannel{}
+
+	for rows.Next() {
+		var ch NotificationChannel
+
+		err := rows.Scan(
+			&ch.ID,
+			&ch.UserID,
+			&ch.Name,
+			&ch.Type,
+			&ch.Config,
+			&ch.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, ch)
+	}
+
+	return channels, nil
+}
+
+func (s *NotificationStorage) CreateDelivery(ctx context.Context, d NotificationDelivery) (*string, error) {
+	query := `
+		INSERT INTO "notification_delivery" (channel_id, website_id, incident_id, event)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, query, d.ChannelID, d.WebsiteID, d.IncidentID, d.Event).Scan(&d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d.ID, nil
+}
+
+// UpdateDelivery records the outcome of an attempt to send a notification.
+func (s *NotificationStorage) UpdateDelivery(ctx context.Context, id string, status string, attempts int, deliveryErr error) error {
+	query := `
+		UPDATE "notification_delivery"
+		SET
+			status = $2,
+			attempts = $3,
+			error = $4,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE NULL END
+		WHERE id = $1
+	`
+
+	var errMsg *string
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		errMsg = &msg
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, id, status, attempts, errMsg)
+	return err
+}
+
+func (s *NotificationStorage) GetDeliveries(ctx context.Context, channelId string, limit int) ([]NotificationDelivery, error) {
+	query := `
+		SELECT id, channel_id, website_id, incident_id, event, status, attempts, error, created_at, delivered_at
+		FROM "notification_delivery"
+		WHERE channel_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, channelId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []NotificationDelivery = []NotificationDelivery{}
+
+	for rows.Next() {
+		var d NotificationDelivery
+
+		err := rows.Scan(
+			&d.ID,
+			&d.ChannelID,
+			&d.WebsiteID,
+			&d.IncidentID,
+			&d.Event,
+			&d.Status,
+			&d.Attempts,
+			&d.Error,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+var (
+	ErrChannelAlreadyAttached = errors.New("notification channel already attached to website")
+	ErrPrivateDestination     = errors.New("destination is not a public address")
+)
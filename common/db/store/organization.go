@@ -0,0 +1,493 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// RoleAtLeast reports whether role grants everything min does.
+func RoleAtLeast(role string, min string) bool {
+	return roleRank[role] >= roleRank[min] && roleRank[role] > 0
+}
+
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type OrganizationMember struct {
+	UserID    string    `json:"userId"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Image     string    `json:"image"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type OrganizationInvite struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  *string    `json:"invitedBy,omitempty"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type OrganizationStorage struct {
+	db *pgxpool.Pool
+}
+
+// createOrganization inserts an organization with userId as its owner inside tx.
+func createOrganization(ctx context.Context, tx pgx.Tx, name string, personal bool, userId string) (string, error) {
+	orgQuery := `
+		INSERT INTO "organization" (name, personal, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	orgCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var id string
+	err := tx.QueryRow(orgCtx, orgQuery, name, personal, userId).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	memberQuery := `
+		INSERT INTO "organization_member" (organization_id, user_id, role)
+		VALUES ($1, $2, 'owner')
+	`
+
+	memberCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.Exec(memberCtx, memberQuery, id, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *OrganizationStorage) CreateOrganization(ctx context.Context, name string, userId string) (*string, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	id, err := createOrganization(ctx, tx, name, false, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// GetOrganizations lists the organizations of a user with the user's role in each,
+// personal organization first.
+func (s *OrganizationStorage) GetOrganizations(ctx context.Context, userId string) ([]Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.personal, om.role, o.created_at
+		FROM "organization" o
+		JOIN "organization_member" om ON o.id = om.organization_id
+		WHERE om.user_id = $1
+		ORDER BY o.personal DESC, o.created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var organizations []Organization = []Organization{}
+
+	for rows.Next() {
+		var o Organization
+
+		err := rows.Scan(
+			&o.ID,
+			&o.Name,
+			&o.Personal,
+			&o.Role,
+			&o.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		organizations = append(organizations, o)
+	}
+
+	return organizations, nil
+}
+
+// GetDefaultOrganization returns the organization a user lands in after logging in.
+func (s *OrganizationStorage) GetDefaultOrganization(ctx context.Context, userId string) (*Organization, error) {
+	organizations, err := s.GetOrganizations(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(organizations) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &organizations[0], nil
+}
+
+func (s *OrganizationStorage) GetMembership(ctx context.Context, organizationId string, userId string) (*Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.personal, om.role, o.created_at
+		FROM "organization" o
+		JOIN "organization_member" om ON o.id = om.organization_id
+		WHERE o.id = $1 AND om.user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var o Organization
+	err := s.db.QueryRow(ctx, query, organizationId, userId).Scan(
+		&o.ID,
+		&o.Name,
+		&o.Personal,
+		&o.Role,
+		&o.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (s *OrganizationStorage) UpdateOrganization(ctx context.Context, id string, name string) error {
+	query := `
+		UPDATE "organization"
+		SET name = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, name, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteOrganization removes a shared organization. Personal organizations and
+// organizations still owning websites cannot be deleted.
+func (s *OrganizationStorage) DeleteOrganization(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM "organization"
+		WHERE id = $1 AND NOT personal
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23503" {
+			return ErrOrganizationNotEmpty
+		}
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrPersonalOrganization
+	}
+
+	return nil
+}
+
+func (s *OrganizationStorage) GetMembers(ctx context.Context, organizationId string) ([]OrganizationMember, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.image, om.role, om.created_at
+		FROM "organization_member" om
+		JOIN "user" u ON om.user_id = u.id
+		WHERE om.organization_id = $1
+		ORDER BY om.created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []OrganizationMember = []OrganizationMember{}
+
+	for rows.Next() {
+		var m OrganizationMember
+
+		err := rows.Scan(
+			&m.UserID,
+			&m.Name,
+			&m.Email,
+			&m.Image,
+			&m.Role,
+			&m.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a member, refusing to demote the last owner.
+func (s *OrganizationStorage) UpdateMemberRole(ctx context.Context, organizationId string, userId string, role string) error {
+	query := `
+		UPDATE "organization_member"
+		SET role = $3
+		WHERE
+			organization_id = $1
+			AND user_id = $2
+			AND (
+				$3 = 'owner'
+				OR role <> 'owner'
+				OR (SELECT COUNT(*) FROM "organization_member" WHERE organization_id = $1 AND role = 'owner') > 1
+			)
+	`
+
+	return s.execMemberChange(ctx, query, organizationId, userId, role)
+}
+
+// RemoveMember removes a member, refusing to remove the last owner.
+func (s *OrganizationStorage) RemoveMember(ctx context.Context, organizationId string, userId string) error {
+	query := `
+		DELETE FROM "organization_member"
+		WHERE
+			organization_id = $1
+			AND user_id = $2
+			AND (
+				role <> 'owner'
+				OR (SELECT COUNT(*) FROM "organization_member" WHERE organization_id = $1 AND role = 'owner') > 1
+			)
+	`
+
+	return s.execMemberChange(ctx, query, organizationId, userId)
+}
+
+func (s *OrganizationStorage) execMemberChange(ctx context.Context, query string, organizationId string, userId string, args ...any) error {
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(queryCtx, query, append([]any{organizationId, userId}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Tell a missing member apart from the last owner guard
+	_, err = s.GetMembership(ctx, organizationId, userId)
+	if err != nil {
+		return err
+	}
+
+	return ErrLastOwner
+}
+
+func (s *OrganizationStorage) CreateInvite(ctx context.Context, organizationId string, invite OrganizationInvite, tokenHash string) (*string, error) {
+	query := `
+		INSERT INTO "organization_invite" (organization_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, query, organizationId, invite.Email, invite.Role, tokenHash, invite.InvitedBy, invite.ExpiresAt).Scan(&invite.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite.ID, nil
+}
+
+func (s *OrganizationStorage) GetInvites(ctx context.Context, organizationId string) ([]OrganizationInvite, error) {
+	query := `
+		SELECT id, email, role, invited_by, expires_at, accepted_at, created_at
+		FROM "organization_invite"
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []OrganizationInvite = []OrganizationInvite{}
+
+	for rows.Next() {
+		var i OrganizationInvite
+
+		err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Role,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		invites = append(invites, i)
+	}
+
+	return invites, nil
+}
+
+func (s *OrganizationStorage) DeleteInvite(ctx context.Context, organizationId string, id string) error {
+	query := `
+		DELETE FROM "organization_invite"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AcceptInvite adds the user to the organization of a pending invite addressed to
+// their email and returns the organization id.
+func (s *OrganizationStorage) AcceptInvite(ctx context.Context, tokenHash string, userId string) (*string, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	inviteQuery := `
+		UPDATE "organization_invite" oi
+		SET accepted_at = NOW()
+		FROM "user" u
+		WHERE
+			oi.token_hash = $1
+			AND u.id = $2
+			AND lower(oi.email) = lower(u.email)
+			AND oi.accepted_at IS NULL
+			AND oi.expires_at > NOW()
+		RETURNING oi.organization_id, oi.role
+	`
+
+	inviteCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var organizationId, role string
+	err = tx.QueryRow(inviteCtx, inviteQuery, tokenHash, userId).Scan(&organizationId, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidInvite
+		}
+
+		return nil, err
+	}
+
+	memberQuery := `
+		INSERT INTO "organization_member" (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO NOTHING
+	`
+
+	memberCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.Exec(memberCtx, memberQuery, organizationId, userId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &organizationId, nil
+}
+
+var (
+	ErrLastOwner            = errors.New("organization must keep at least one owner")
+	ErrInvalidInvite        = errors.New("invite is invalid, expired or for another email")
+	ErrPersonalOrganization = errors.New("organization does not exist or is personal")
+	ErrOrganizationNotEmpty = errors.New("organization still owns websites")
+)
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ticks of deleted websites are exported and purged a day at a time, so each query
+// stays small even for websites with years of history.
+var TickPurgeChunk = 24 * time.Hour
+
+// DailyHistory is a day of checks of a website in a region, from the daily aggregate
+// that outlives the raw ticks.
+type DailyHistory struct {
+	Date              time.Time `json:"date"`
+	Region            string    `json:"region"`
+	Checks            int64     `json:"checks"`
+	Up                int64     `json:"up"`
+	Down              int64     `json:"down"`
+	Maintenance       int64     `json:"maintenance"`
+	AvgResponseTimeMS *float64  `json:"avg_response_time_ms"`
+}
+
+// ExportTicks calls fn with every stored tick of a website, oldest first.
+func (s *WebsiteTickStorage) ExportTicks(ctx context.Context, websiteID string, fn func(WebsiteTick) error) error {
+	rangeQuery := `
+		SELECT MIN(time), MAX(time)
+		FROM "website_tick"
+		WHERE website_id = $1
+	`
+
+	rangeCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var first, last *time.Time
+
+	if err := s.db.QueryRow(rangeCtx, rangeQuery, websiteID).Scan(&first, &last); err != nil {
+		return err
+	}
+
+	if first == nil {
+		return nil
+	}
+
+	query := `
+		SELECT ` + websiteTickColumns + `
+		FROM "website_tick" wt
+		WHERE
+			wt.website_id = $1
+			AND wt.time >= $2
+			AND wt.time < $3
+		ORDER BY wt.time ASC
+	`
+
+	for from := *first; !from.After(*last); from = from.Add(TickPurgeChunk) {
+		if err := s.exportChunk(ctx, query, websiteID, from, from.Add(TickPurgeChunk), fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *WebsiteTickStorage) exportChunk(ctx context.Context, query string, websiteID string, from time.Time, to time.Time, fn func(WebsiteTick) error) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanWebsiteTick(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(*t); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetDailyHistory returns every day of checks of a website by region, oldest first.
+func (s *WebsiteTickStorage) GetDailyHistory(ctx context.Context, websiteID string) ([]DailyHistory, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			a.bucket,
+			r.name,
+			a.checks,
+			a.up,
+			a.down,
+			a.maintenance,
+			(a.response_time_sum::float / NULLIF(a.response_time_count, 0))
+		FROM %s a
+		JOIN "region" r ON a.region_id = r.id
+		WHERE a.website_id = $1
+		ORDER BY a.bucket ASC, r.name ASC
+	`, dailyTicks.view)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []DailyHistory = []DailyHistory{}
+
+	for rows.Next() {
+		var d DailyHistory
+
+		err := rows.Scan(&d.Date, &d.Region, &d.Checks, &d.Up, &d.Down, &d.Maintenance, &d.AvgResponseTimeMS)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, d)
+	}
+
+	return history, rows.Err()
+}
+
+// PurgeTicks deletes the oldest chunk of ticks of a website and returns how many
+// were deleted, 0 once none are left.
+func (s *WebsiteTickStorage) PurgeTicks(ctx context.Context, websiteID string) (int64, error) {
+	query := `
+		DELETE FROM "website_tick"
+		WHERE
+			website_id = $1
+			AND time < (SELECT MIN(time) FROM "website_tick" WHERE website_id = $1) + $2::bigint * INTERVAL '1 second'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, websiteID, int64(TickPurgeChunk.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// SetExported records that the ticks of a deleted website were archived, so a purge
+// resumed after a failure doesn't archive what is left of them again.
+func (s *WebsiteStorage) SetExported(ctx context.Context, id string) error {
+	query := `
+		UPDATE "website"
+		SET exported_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, id)
+	return err
+}
+
+// PurgeWebsite deletes a deleted website past its restore window with its regions
+// and incidents, once its ticks were purged. Everything else referencing the
+// website is deleted with it.
+func (s *WebsiteStorage) PurgeWebsite(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+		SELECT id
+		FROM "website"
+		WHERE id = $1 AND deleted_at IS NOT NULL AND purge_after <= NOW()
+		FOR UPDATE
+	`
+
+	lockCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if err := tx.QueryRow(lockCtx, lockQuery, id).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM website_region WHERE website_id = $1`,
+		`DELETE FROM incident WHERE website_id = $1`,
+		`DELETE FROM website WHERE id = $1`,
+	}
+
+	for _, query := range queries {
+		queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.Exec(queryCtx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,450 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SLOWindow string
+
+const (
+	SLORolling  SLOWindow = "rolling"
+	SLOCalendar SLOWindow = "calendar"
+)
+
+type SLOState string
+
+const (
+	SLOOk        SLOState = "ok"
+	SLOSlowBurn  SLOState = "slow_burn"
+	SLOFastBurn  SLOState = "fast_burn"
+	SLOExhausted SLOState = "exhausted"
+)
+
+// BurnRateAlert is breached when the error budget burns at least Rate times faster
+// than sustainable over both the long and the short window, so a burn that
+// stopped recovers quickly.
+type BurnRateAlert struct {
+	State SLOState
+	Long  time.Duration
+	Short time.Duration
+	Rate  float64
+}
+
+// BurnRateAlerts are checked in order, the first breached sets the state. A fast
+// burn spends 2% of a 30 day budget in an hour, a slow burn 5% in 6 hours.
+var BurnRateAlerts = []BurnRateAlert{
+	{SLOFastBurn, time.Hour, 5 * time.Minute, 14.4},
+	{SLOSlowBurn, 6 * time.Hour, 30 * time.Minute, 6},
+}
+
+// Longest rolling window, latency SLIs are computed from the raw ticks
+var MaxSLOWindowDays = 90
+
+// SLO is an availability target of a website over a window. Checks are good when
+// up and, with a latency threshold, answered within it. Unknown checks don't count.
+type SLO struct {
+	ID                 string     `json:"id"`
+	WebsiteID          string     `json:"websiteId"`
+	Name               string     `json:"name"`
+	Target             float64    `json:"target"`
+	Window             SLOWindow  `json:"window"`
+	WindowDays         int        `json:"windowDays"`
+	LatencyThresholdMS *int64     `json:"latencyThresholdMs,omitempty"`
+	State              SLOState   `json:"state"`
+	StateChangedAt     *time.Time `json:"stateChangedAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// WindowRange returns the window of the SLO ending at now.
+func (s SLO) WindowRange(now time.Time) Range {
+	if s.Window == SLOCalendar {
+		now = now.UTC()
+		return Range{From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), To: now}
+	}
+
+	return Range{From: now.AddDate(0, 0, -s.WindowDays), To: now}
+}
+
+// ErrorRate is the share of bad checks the target allows.
+func (s SLO) ErrorRate() float64 {
+	return 1 - s.Target/100
+}
+
+type BurnRate struct {
+	Window    string  `json:"window"`
+	Checks    int64   `json:"checks"`
+	BadChecks int64   `json:"badChecks"`
+	Rate      float64 `json:"rate"`
+}
+
+// SLOStatus is the SLI and error budget of an SLO over its window, and the burn
+// rates of the windows of BurnRateAlerts.
+type SLOStatus struct {
+	SLO
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Checks    int64     `json:"checks"`
+	BadChecks int64     `json:"badChecks"`
+	// Percentage of good checks, 100 without checks
+	SLI float64 `json:"sli"`
+	// Bad checks the target allows over the checks of the window
+	ErrorBudget float64 `json:"errorBudget"`
+	// Percentage of the error budget left, negative once overspent
+	BudgetRemaining float64    `json:"budgetRemaining"`
+	BurnRates       []BurnRate `json:"burnRates"`
+	// State of the budget now, the stored state is the last evaluation
+	CurrentState SLOState `json:"currentState"`
+}
+
+type SLOStorage struct {
+	db *pgxpool.Pool
+}
+
+const sloColumns = `
+	id, website_id, name, target::float8, "window"::text, window_days,
+	latency_threshold_ms, state::text, state_changed_at, created_at
+`
+
+func scanSLO(row pgx.Row) (*SLO, error) {
+	var s SLO
+
+	err := row.Scan(
+		&s.ID,
+		&s.WebsiteID,
+		&s.Name,
+		&s.Target,
+		&s.Window,
+		&s.WindowDays,
+		&s.LatencyThresholdMS,
+		&s.State,
+		&s.StateChangedAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (s *SLOStorage) CreateSLO(ctx context.Context, slo SLO) (*string, error) {
+	query := `
+		INSERT INTO "website_slo" (website_id, name, target, "window", window_days, latency_threshold_ms)
+		VALUES ($1, $2, $3, $4::slo_window, $5, $6)
+		RETURNING id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var id string
+	err := s.db.QueryRow(ctx, query, slo.WebsiteID, slo.Name, slo.Target, string(slo.Window), slo.WindowDays, slo.LatencyThresholdMS).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func (s *SLOStorage) GetSLOs(ctx context.Context, websiteID string) ([]SLO, error) {
+	return s.getSLOs(ctx, `WHERE website_id = $1 ORDER BY created_at`, websiteID)
+}
+
+// GetAllSLOs returns the SLOs of every website not deleted, for the db-worker to
+// evaluate.
+func (s *SLOStorage) GetAllSLOs(ctx context.Context) ([]SLO, error) {
+	return s.getSLOs(ctx, `WHERE website_id IN (SELECT id FROM "website" WHERE deleted_at IS NULL) ORDER BY website_id, created_at`)
+}
+
+func (s *SLOStorage) getSLOs(ctx context.Context, where string, args ...any) ([]SLO, error) {
+	query := `
+		SELECT ` + sloColumns + `
+		FROM "website_slo"
+		` + where
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slos []SLO = []SLO{}
+
+	for rows.Next() {
+		slo, err := scanSLO(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		slos = append(slos, *slo)
+	}
+
+	return slos, rows.Err()
+}
+
+func (s *SLOStorage) GetSLO(ctx context.Context, id string, websiteID string) (*SLO, error) {
+	query := `
+		SELECT ` + sloColumns + `
+		FROM "website_slo"
+		WHERE id = $1 AND website_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	slo, err := scanSLO(s.db.QueryRow(ctx, query, id, websiteID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return slo, nil
+}
+
+func (s *SLOStorage) UpdateSLO(ctx context.Context, slo SLO) error {
+	query := `
+		UPDATE "website_slo"
+		SET name = $1, target = $2, "window" = $3::slo_window, window_days = $4, latency_threshold_ms = $5
+		WHERE id = $6 AND website_id = $7
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, slo.Name, slo.Target, string(slo.Window), slo.WindowDays, slo.LatencyThresholdMS, slo.ID, slo.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SLOStorage) DeleteSLO(ctx context.Context, id string, websiteID string) error {
+	query := `
+		DELETE FROM "website_slo"
+		WHERE id = $1 AND website_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, websiteID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SetState stores the state of an evaluation and reports whether it changed.
+func (s *SLOStorage) SetState(ctx context.Context, id string, state SLOState) (bool, error) {
+	query := `
+		UPDATE "website_slo"
+		SET state = $1::slo_state, state_changed_at = NOW()
+		WHERE id = $2 AND state <> $1::slo_state
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, string(state), id)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// GetStatus computes the SLI, error budget and burn rates of an SLO at now. The
+// burn rates come from the raw ticks of their short windows. The SLI of an
+// availability SLO comes from the tick aggregates, only a latency threshold needs
+// the raw ticks of the whole window.
+func (s *SLOStorage) GetStatus(ctx context.Context, slo SLO, now time.Time) (*SLOStatus, error) {
+	window := slo.WindowRange(now)
+
+	// The bad checks of every window read from the raw ticks
+	var windows []time.Time
+	if slo.LatencyThresholdMS != nil {
+		windows = append(windows, window.From)
+	}
+	for _, a := range BurnRateAlerts {
+		windows = append(windows, now.Add(-a.Long), now.Add(-a.Short))
+	}
+
+	counts, err := s.countBadChecks(ctx, slo, windows, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// counts holds the checks and bad checks of the SLO window first
+	if slo.LatencyThresholdMS == nil {
+		checks, bad, err := s.countAggregatedChecks(ctx, slo.WebsiteID, window)
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append([]int64{checks, bad}, counts...)
+	}
+
+	status := &SLOStatus{
+		SLO:       slo,
+		From:      window.From,
+		To:        window.To,
+		Checks:    counts[0],
+		BadChecks: counts[1],
+		SLI:       100,
+		BurnRates: []BurnRate{},
+	}
+
+	status.ErrorBudget = float64(status.Checks) * slo.ErrorRate()
+	status.BudgetRemaining = 100
+	if status.Checks > 0 {
+		status.SLI = 100 * float64(status.Checks-status.BadChecks) / float64(status.Checks)
+		status.BudgetRemaining = 100 * (1 - float64(status.BadChecks)/status.ErrorBudget)
+	}
+
+	burn := func(i int, d time.Duration) BurnRate {
+		b := BurnRate{Window: formatWindow(d), Checks: counts[2*i], BadChecks: counts[2*i+1]}
+		if b.Checks > 0 {
+			b.Rate = float64(b.BadChecks) / float64(b.Checks) / slo.ErrorRate()
+		}
+		return b
+	}
+
+	status.CurrentState = SLOOk
+	for i, a := range BurnRateAlerts {
+		long, short := burn(2*i+1, a.Long), burn(2*i+2, a.Short)
+		status.BurnRates = append(status.BurnRates, long, short)
+
+		if status.CurrentState == SLOOk && long.Rate >= a.Rate && short.Rate >= a.Rate {
+			status.CurrentState = a.State
+		}
+	}
+
+	if status.CurrentState == SLOOk && status.Checks > 0 && status.BudgetRemaining <= 0 {
+		status.CurrentState = SLOExhausted
+	}
+
+	return status, nil
+}
+
+// countBadChecks counts the checks and bad checks since each of windows in one pass
+// over the raw ticks, in pairs.
+func (s *SLOStorage) countBadChecks(ctx context.Context, slo SLO, windows []time.Time, now time.Time) ([]int64, error) {
+	var columns []string
+	for i := range windows {
+		columns = append(columns,
+			fmt.Sprintf("COUNT(*) FILTER (WHERE time >= $%d)", i+5),
+			fmt.Sprintf("COUNT(*) FILTER (WHERE time >= $%d AND bad)", i+5),
+		)
+	}
+
+	query := `
+		WITH checks AS (
+			SELECT
+				time,
+				status = 'down' OR ($4::int IS NOT NULL AND response_time_ms > $4) AS bad
+			FROM "website_tick"
+			WHERE
+				website_id = $1
+				AND time BETWEEN $2 AND $3
+				AND status IN ('up', 'down')
+		)
+		SELECT ` + strings.Join(columns, ", ") + `
+		FROM checks
+	`
+
+	earliest := now
+	args := []any{slo.WebsiteID, earliest, now, slo.LatencyThresholdMS}
+	for _, w := range windows {
+		if w.Before(earliest) {
+			earliest = w
+		}
+		args = append(args, w)
+	}
+	args[1] = earliest
+
+	counts := make([]int64, len(windows)*2)
+	var targets []any
+	for i := range counts {
+		targets = append(targets, &counts[i])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, query, args...).Scan(targets...)
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// countAggregatedChecks counts the checks and down checks of a website over r from
+// the tick aggregates, the 5 minute buckets up to the first whole hour and the
+// hourly buckets after it.
+func (s *SLOStorage) countAggregatedChecks(ctx context.Context, websiteID string, r Range) (int64, int64, error) {
+	hour := r.From.Truncate(time.Hour)
+	if hour.Before(r.From) {
+		hour = hour.Add(time.Hour)
+	}
+
+	query := `
+		SELECT
+			COALESCE(SUM(up + down), 0)::bigint,
+			COALESCE(SUM(down), 0)::bigint
+		FROM (
+			SELECT up, down
+			FROM "website_tick_5m"
+			WHERE website_id = $1 AND bucket >= $2 AND bucket < $3
+			UNION ALL
+			SELECT up, down
+			FROM "website_tick_1h"
+			WHERE website_id = $1 AND bucket >= $3 AND bucket <= $4
+		) buckets
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var checks, bad int64
+	err := s.db.QueryRow(ctx, query, websiteID, r.From, hour, r.To).Scan(&checks, &bad)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return checks, bad, nil
+}
+
+// formatWindow writes a window like 5m, 6h or 3d.
+func formatWindow(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
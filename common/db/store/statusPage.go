@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatusPageWebsite struct {
+	WebsiteID   string `json:"websiteId"`
+	Url         string `json:"url,omitempty"`
+	DisplayName string `json:"displayName"`
+	ShowUrl     bool   `json:"showUrl"`
+	Position    int    `json:"position"`
+}
+
+type StatusPage struct {
+	ID          string              `json:"id"`
+	Slug        string              `json:"slug"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Websites    []StatusPageWebsite `json:"websites"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+}
+
+type StatusPageStorage struct {
+	db *pgxpool.Pool
+}
+
+func (s *StatusPageStorage) CreateStatusPage(ctx context.Context, p StatusPage, userId string, organizationId string) (*string, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO "status_page" (user_id, organization_id, slug, title, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err = tx.QueryRow(queryCtx, query, userId, organizationId, p.Slug, p.Title, p.Description).Scan(&p.ID)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" {
+			return nil, ErrDuplicateSlug
+		}
+
+		return nil, err
+	}
+
+	if err := insertStatusPageWebsites(ctx, tx, p, organizationId); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p.ID, nil
+}
+
+// insertStatusPageWebsites adds the websites of a page and fails on any outside the organization.
+func insertStatusPageWebsites(ctx context.Context, tx pgx.Tx, p StatusPage, organizationId string) error {
+	query := `
+		INSERT INTO "status_page_website" (status_page_id, website_id, display_name, show_url, position)
+		SELECT $1, id, $3, $4, $5
+		FROM website
+		WHERE id = $2 AND organization_id = $6 AND deleted_at IS NULL
+	`
+
+	for i, w := range p.Websites {
+		queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.Exec(queryCtx, query, p.ID, w.WebsiteID, w.DisplayName, w.ShowUrl, i, organizationId)
+		if err != nil {
+			return err
+		}
+
+		if res.RowsAffected() == 0 {
+			return ErrStatusPageWebsite
+		}
+	}
+
+	return nil
+}
+
+func (s *StatusPageStorage) GetStatusPages(ctx context.Context, organizationId string) ([]StatusPage, error) {
+	query := `
+		SELECT id, slug, title, description, created_at, updated_at
+		FROM "status_page"
+		WHERE organization_id = $1
+		ORDER BY created_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []StatusPage = []StatusPage{}
+
+	for rows.Next() {
+		var p StatusPage
+
+		err := rows.Scan(
+			&p.ID,
+			&p.Slug,
+			&p.Title,
+			&p.Description,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, p)
+	}
+
+	return pages, nil
+}
+
+func (s *StatusPageStorage) GetStatusPageById(ctx context.Context, id string, organizationId string) (*StatusPage, error) {
+	query := `
+		SELECT id, slug, title, description, created_at, updated_at
+		FROM "status_page"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	return s.getStatusPage(ctx, query, id, organizationId)
+}
+
+// GetStatusPageBySlug is used by the public endpoint and does not check the owner.
+func (s *StatusPageStorage) GetStatusPageBySlug(ctx context.Context, slug string) (*StatusPage, error) {
+	query := `
+		SELECT id, slug, title, description, created_at, updated_at
+		FROM "status_page"
+		WHERE slug = $1
+	`
+
+	return s.getStatusPage(ctx, query, slug)
+}
+
+func (s *StatusPageStorage) getStatusPage(ctx context.Context, query string, args ...any) (*StatusPage, error) {
+	pageCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var p StatusPage
+	err := s.db.QueryRow(pageCtx, query, args...).Scan(
+		&p.ID,
+		&p.Slug,
+		&p.Title,
+		&p.Description,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	websitesQuery := `
+		SELECT spw.website_id, w.url, spw.display_name, spw.show_url, spw.position
+		FROM "status_page_website" spw
+		JOIN "website" w ON spw.website_id = w.id
+		WHERE spw.status_page_id = $1 AND w.deleted_at IS NULL
+		ORDER BY spw.position ASC
+	`
+
+	websitesCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(websitesCtx, websitesQuery, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p.Websites = []StatusPageWebsite{}
+
+	for rows.Next() {
+		var w StatusPageWebsite
+
+		err := rows.Scan(
+			&w.WebsiteID,
+			&w.Url,
+			&w.DisplayName,
+			&w.ShowUrl,
+			&w.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Websites = append(p.Websites, w)
+	}
+
+	return &p, nil
+}
+
+func (s *StatusPageStorage) UpdateStatusPage(ctx context.Context, p StatusPage, organizationId string) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE "status_page"
+		SET slug = $1, title = $2, description = $3, updated_at = NOW()
+		WHERE id = $4 AND organization_id = $5
+	`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.Exec(queryCtx, query, p.Slug, p.Title, p.Description, p.ID, organizationId)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" {
+			return ErrDuplicateSlug
+		}
+
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	deleteQuery := `
+		DELETE FROM "status_page_website"
+		WHERE status_page_id = $1
+	`
+
+	queryCtx, cancel = context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.Exec(queryCtx, deleteQuery, p.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertStatusPageWebsites(ctx, tx, p, organizationId); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *StatusPageStorage) DeleteStatusPage(ctx context.Context, id string, organizationId string) error {
+	query := `
+		DELETE FROM "status_page"
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+var (
+	ErrDuplicateSlug     = errors.New("status page slug already exists")
+	ErrStatusPageWebsite = errors.New("status page references a website outside the organization")
+)
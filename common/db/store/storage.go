@@ -13,17 +13,35 @@ var (
 )
 
 type Storage struct {
-	Website     WebsiteStorage
-	Region      RegionStorage
-	User        UserStorage
-	WebsiteTick WebsiteTickStorage
+	Website      WebsiteStorage
+	Region       RegionStorage
+	User         UserStorage
+	WebsiteTick  WebsiteTickStorage
+	Incident     IncidentStorage
+	Notification NotificationStorage
+	StatusPage   StatusPageStorage
+	Organization OrganizationStorage
+	Certificate  CertificateStorage
+	DNS          DNSStorage
+	Heartbeat    HeartbeatStorage
+	SLO          SLOStorage
+	Maintenance  MaintenanceStorage
 }
 
 func NewStorage(db *pgxpool.Pool) Storage {
 	return Storage{
-		Website:     WebsiteStorage{db},
-		Region:      RegionStorage{db},
-		User:        UserStorage{db},
-		WebsiteTick: WebsiteTickStorage{db},
+		Website:      WebsiteStorage{db},
+		Region:       RegionStorage{db},
+		User:         UserStorage{db},
+		WebsiteTick:  WebsiteTickStorage{db},
+		Incident:     IncidentStorage{db},
+		Notification: NotificationStorage{db},
+		StatusPage:   StatusPageStorage{db},
+		Organization: OrganizationStorage{db},
+		Certificate:  CertificateStorage{db},
+		DNS:          DNSStorage{db},
+		Heartbeat:    HeartbeatStorage{db},
+		SLO:          SLOStorage{db},
+		Maintenance:  MaintenanceStorage{db},
 	}
 }
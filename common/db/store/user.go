@@ -104,6 +104,12 @@ func (s *UserStorage) Create(ctx context.Context, u User, provider string) (*Use
 		return nil, err
 	}
 
+	// Every user owns a personal organization
+	_, err = createOrganization(ctx, tx, user.Name, true, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		return nil, err
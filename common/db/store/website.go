@@ -17,13 +17,26 @@ type Website struct {
 	Regions   []Region      `json:"regions"`
 	CreatedAt time.Time     `json:"created_at"`
 	CreatedBy string        `json:"created_by"`
+	CheckType CheckType     `json:"type"`
+	Check     CheckSpec     `json:"check"`
+	// A certificate expiring within this many days is in the warning state
+	CertificateExpiryDays int `json:"certificate_expiry_days"`
+	// Hash of the ingestion token of heartbeat monitors
+	HeartbeatTokenHash *string `json:"-"`
+	// Paused websites aren't checked, until ResumeAt when set
+	PausedAt *time.Time `json:"paused_at"`
+	ResumeAt *time.Time `json:"resume_at"`
+}
+
+func (w Website) Paused() bool {
+	return w.PausedAt != nil
 }
 
 type WebsiteStorage struct {
 	db *pgxpool.Pool
 }
 
-func (s *WebsiteStorage) CreateWebsite(ctx context.Context, w Website, userId string) (*string, error) {
+func (s *WebsiteStorage) CreateWebsite(ctx context.Context, w Website, userId string, organizationId string) (*string, error) {
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, err
@@ -32,14 +45,14 @@ func (s *WebsiteStorage) CreateWebsite(ctx context.Context, w Website, userId st
 	defer tx.Rollback(ctx)
 
 	websiteQuery := `
-			INSERT INTO "website" (url, frequency, created_by)
-			VALUES ($1, $2, $3)
+			INSERT INTO "website" (url, frequency, created_by, organization_id, check_type, check_spec, certificate_expiry_days, heartbeat_token_hash)
+			VALUES ($1, $2, $3, $4, $5::check_type, $6, $7, $8)
 			RETURNING id
 	`
 	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err = tx.QueryRow(queryCtx, websiteQuery, w.Url, w.Frequency, userId).Scan(&w.ID)
+	err = tx.QueryRow(queryCtx, websiteQuery, w.Url, w.Frequency, userId, organizationId, string(w.CheckType), w.Check, w.CertificateExpiryDays, w.HeartbeatTokenHash).Scan(&w.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -67,13 +80,18 @@ func (s *WebsiteStorage) CreateWebsite(ctx context.Context, w Website, userId st
 	return &w.ID, nil
 }
 
-func (s *WebsiteStorage) GetWebsiteById(ctx context.Context, id string, userId string) (*Website, error) {
+func (s *WebsiteStorage) GetWebsiteById(ctx context.Context, id string, organizationId string) (*Website, error) {
 	query := `
 		SELECT
             w.id,
             w.url,
             w.frequency,
             w.created_at,
+            w.check_type::text,
+            w.check_spec,
+            w.certificate_expiry_days,
+            w.paused_at,
+            w.resume_at,
             r.id,
             r.name
         FROM
@@ -83,13 +101,13 @@ func (s *WebsiteStorage) GetWebsiteById(ctx context.Context, id string, userId s
         LEFT JOIN
             region r ON wr.region_id = r.id
         WHERE
-            w.id = $1 AND w.created_by = $2
+            w.id = $1 AND w.organization_id = $2 AND w.deleted_at IS NULL
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.Query(ctx, query, id, userId)
+	rows, err := s.db.Query(ctx, query, id, organizationId)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -110,6 +128,11 @@ func (s *WebsiteStorage) GetWebsiteById(ctx context.Context, id string, userId s
 			&website.Url,
 			&website.Frequency,
 			&website.CreatedAt,
+			&website.CheckType,
+			&website.Check,
+			&website.CertificateExpiryDays,
+			&website.PausedAt,
+			&website.ResumeAt,
 			&region.ID,
 			&region.Name,
 		)
@@ -127,63 +150,148 @@ func (s *WebsiteStorage) GetWebsiteById(ctx context.Context, id string, userId s
 	return website, nil
 }
 
-func (s *WebsiteStorage) GetWebsiteByFrequency(ctx context.Context, freq string) ([]redisClient.RedisPayload, error) {
+// GetWebsiteUrl looks up a website without checking its owner, for internal services.
+func (s *WebsiteStorage) GetWebsiteUrl(ctx context.Context, id string) (string, error) {
+	query := `
+		SELECT url
+		FROM website
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var url string
+	err := s.db.QueryRow(ctx, query, id).Scan(&url)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+
+		return "", err
+	}
+
+	return url, nil
+}
+
+// UpdateContentHash stores the latest content hash of a website and returns the
+// one it replaced. Hashes checked before the stored one are ignored and return nil.
+func (s *WebsiteStorage) UpdateContentHash(ctx context.Context, id string, hash string, checkedAt time.Time) (*string, error) {
+	query := `
+		WITH previous AS (
+			SELECT content_hash
+			FROM website
+			WHERE id = $1
+			FOR UPDATE
+		)
+		UPDATE website
+		SET content_hash = $2, content_checked_at = $3
+		WHERE id = $1 AND (content_checked_at IS NULL OR content_checked_at < $3)
+		RETURNING (SELECT content_hash FROM previous)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var previous *string
+	err := s.db.QueryRow(ctx, query, id, hash, checkedAt).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// ScheduledWebsite is a website as seen by the publisher, with one payload per region.
+type ScheduledWebsite struct {
+	ID        string
+	Frequency time.Duration
+	CheckType CheckType
+	Payloads  []redisClient.RedisPayload
+}
+
+// GetScheduledWebsites returns every website that isn't paused with its regions,
+// for the publisher.
+func (s *WebsiteStorage) GetScheduledWebsites(ctx context.Context) ([]ScheduledWebsite, error) {
 	query := `
 		SELECT
             w.id,
             w.url,
+            w.frequency,
+            w.check_type::text,
+            w.check_spec,
             r.name
         FROM
             website w
-        LEFT JOIN
+        JOIN
             website_region wr ON w.id = wr.website_id
-        LEFT JOIN
+        JOIN
             region r ON wr.region_id = r.id
         WHERE
-            w.frequency = $1
+            w.paused_at IS NULL AND w.deleted_at IS NULL
+        ORDER BY
+            w.id
 	 `
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.Query(ctx, query, freq)
+	rows, err := s.db.Query(ctx, query)
 	if err != nil {
-		switch {
-		case errors.Is(err, pgx.ErrNoRows):
-			return nil, ErrNotFound
-		default:
-			return nil, err
-		}
+		return nil, err
 	}
 	defer rows.Close()
 
-	var payload []redisClient.RedisPayload
+	var websites []ScheduledWebsite = []ScheduledWebsite{}
 
 	for rows.Next() {
+		var w ScheduledWebsite
 		var p redisClient.RedisPayload
 
 		err = rows.Scan(
 			&p.ID,
 			&p.Url,
+			&w.Frequency,
+			&p.CheckType,
+			&p.Check,
 			&p.RegionName,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		payload = append(payload, p)
+		if len(websites) > 0 {
+			lastWebsite := &websites[len(websites)-1]
+
+			if lastWebsite.ID == p.ID {
+				lastWebsite.Payloads = append(lastWebsite.Payloads, p)
+				continue
+			}
+		}
+
+		w.ID = p.ID
+		w.CheckType = CheckType(p.CheckType)
+		w.Payloads = append(w.Payloads, p)
+		websites = append(websites, w)
 	}
 
-	return payload, nil
+	return websites, nil
 }
 
-func (s *WebsiteStorage) GetAllWebsites(ctx context.Context, userId string) ([]Website, error) {
+func (s *WebsiteStorage) GetAllWebsites(ctx context.Context, organizationId string) ([]Website, error) {
 	query := `
 		SELECT
 						w.id,
 						w.url,
 						w.frequency,
 						w.created_at,
+						w.check_type::text,
+						w.paused_at,
+						w.resume_at,
 						r.name
 				FROM
 						website w
@@ -192,13 +300,13 @@ func (s *WebsiteStorage) GetAllWebsites(ctx context.Context, userId string) ([]W
 				LEFT JOIN
 						region r ON wr.region_id = r.id
 				WHERE
-						w.created_by = $1
+						w.organization_id = $1 AND w.deleted_at IS NULL
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.Query(ctx, query, userId)
+	rows, err := s.db.Query(ctx, query, organizationId)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -220,6 +328,9 @@ func (s *WebsiteStorage) GetAllWebsites(ctx context.Context, userId string) ([]W
 			&w.Url,
 			&w.Frequency,
 			&w.CreatedAt,
+			&w.CheckType,
+			&w.PausedAt,
+			&w.ResumeAt,
 			&r.Name,
 		)
 		if err != nil {
@@ -242,61 +353,120 @@ func (s *WebsiteStorage) GetAllWebsites(ctx context.Context, userId string) ([]W
 	return websites, nil
 }
 
-func (s *WebsiteStorage) DeleteWebsite(ctx context.Context, id string, userId string) error {
-	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+// DeleteWebsite stops checking a website and hides it until purgeAfter, when its
+// ticks and incidents are purged in the background. Until then it can be restored
+// with RestoreWebsite. With export the ticks are archived before the purge.
+func (s *WebsiteStorage) DeleteWebsite(ctx context.Context, id string, organizationId string, purgeAfter time.Time, export bool) error {
+	query := `
+		UPDATE
+			website
+		SET
+			deleted_at = NOW(), purge_after = $1, export_on_purge = $2
+		WHERE
+			id = $3 AND organization_id = $4 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, purgeAfter, export, id, organizationId)
 	if err != nil {
 		return err
 	}
-	//nolint:errcheck
-	defer tx.Rollback(ctx)
 
-	websiteRegionQuery := `
-		DELETE FROM
-			website_region
-		WHERE
-			website_id IN (SELECT id FROM website WHERE id = $1 AND created_by = $2)
-	`
-	websiteTickQuery := `
-		DELETE FROM
-			website_tick
-		WHERE
-			website_id IN (SELECT id FROM website WHERE id = $1 AND created_by = $2)
-	`
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
 
-	websiteQuery := `
-		DELETE FROM
+	return nil
+}
+
+// RestoreWebsite undoes the deletion of a website that wasn't purged yet.
+func (s *WebsiteStorage) RestoreWebsite(ctx context.Context, id string, organizationId string) error {
+	query := `
+		UPDATE
 			website
+		SET
+			deleted_at = NULL, purge_after = NULL, export_on_purge = false
 		WHERE
-			id = $1 AND created_by = $2
+			id = $1 AND organization_id = $2 AND deleted_at IS NOT NULL AND purge_after > NOW()
 	`
-	queries := []string{
-		websiteRegionQuery,
-		websiteTickQuery,
-		websiteQuery,
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
 	}
 
-	for _, query := range queries {
-		queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-		defer cancel()
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeletedWebsite is a deleted website waiting for its purge.
+type DeletedWebsite struct {
+	ID            string     `json:"id"`
+	Url           string     `json:"url"`
+	CheckType     CheckType  `json:"type"`
+	DeletedAt     time.Time  `json:"deleted_at"`
+	PurgeAfter    time.Time  `json:"purge_after"`
+	ExportOnPurge bool       `json:"export_on_purge"`
+	ExportedAt    *time.Time `json:"exported_at,omitempty"`
+}
+
+// GetDeletedWebsites returns the deleted websites of an organization that can still
+// be restored, most recently deleted first.
+func (s *WebsiteStorage) GetDeletedWebsites(ctx context.Context, organizationId string) ([]DeletedWebsite, error) {
+	return s.getDeletedWebsites(ctx, `
+		WHERE organization_id = $1 AND deleted_at IS NOT NULL AND purge_after > NOW()
+		ORDER BY deleted_at DESC
+	`, organizationId)
+}
+
+// GetPurgeableWebsites returns the deleted websites whose restore window is over.
+func (s *WebsiteStorage) GetPurgeableWebsites(ctx context.Context) ([]DeletedWebsite, error) {
+	return s.getDeletedWebsites(ctx, `
+		WHERE deleted_at IS NOT NULL AND purge_after <= NOW()
+		ORDER BY purge_after ASC
+	`)
+}
+
+func (s *WebsiteStorage) getDeletedWebsites(ctx context.Context, where string, args ...any) ([]DeletedWebsite, error) {
+	query := `
+		SELECT id, url, check_type::text, deleted_at, purge_after, export_on_purge, exported_at
+		FROM "website"
+		` + where
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var websites []DeletedWebsite = []DeletedWebsite{}
 
-		_, err := tx.Exec(queryCtx, query, id, userId)
+	for rows.Next() {
+		var w DeletedWebsite
+
+		err := rows.Scan(&w.ID, &w.Url, &w.CheckType, &w.DeletedAt, &w.PurgeAfter, &w.ExportOnPurge, &w.ExportedAt)
 		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return ErrNotFound
-			}
-			return err
+			return nil, err
 		}
-	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return err
+		websites = append(websites, w)
 	}
 
-	return nil
+	return websites, rows.Err()
 }
 
-func (s *WebsiteStorage) UpdateWebsite(ctx context.Context, w Website, userId string) error {
+func (s *WebsiteStorage) UpdateWebsite(ctx context.Context, w Website, organizationId string) error {
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
@@ -308,15 +478,15 @@ func (s *WebsiteStorage) UpdateWebsite(ctx context.Context, w Website, userId st
 		UPDATE
 			website
 		SET
-			url = $1, frequency = $2
+			url = $1, frequency = $2, check_type = $3::check_type, check_spec = $4, certificate_expiry_days = $5
 		WHERE
-			id = $3 AND created_by = $4
+			id = $6 AND organization_id = $7 AND deleted_at IS NULL
 	`
 
 	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	res, err := tx.Exec(queryCtx, updateWebsiteQuery, w.Url, w.Frequency, w.ID, userId)
+	res, err := tx.Exec(queryCtx, updateWebsiteQuery, w.Url, w.Frequency, string(w.CheckType), w.Check, w.CertificateExpiryDays, w.ID, organizationId)
 	if err != nil {
 		return err
 	}
@@ -363,3 +533,80 @@ func (s *WebsiteStorage) UpdateWebsite(ctx context.Context, w Website, userId st
 
 	return nil
 }
+
+// PauseWebsite stops the checks of a website until it is resumed, or until resumeAt
+// when set. Pausing a paused website only changes its resume time.
+func (s *WebsiteStorage) PauseWebsite(ctx context.Context, id string, organizationId string, resumeAt *time.Time) error {
+	query := `
+		UPDATE
+			website
+		SET
+			paused_at = COALESCE(paused_at, NOW()), resume_at = $1
+		WHERE
+			id = $2 AND organization_id = $3 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, resumeAt, id, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ResumeWebsite restarts the checks of a paused website. Resuming a website that
+// isn't paused does nothing.
+func (s *WebsiteStorage) ResumeWebsite(ctx context.Context, id string, organizationId string) error {
+	query := `
+		UPDATE
+			website
+		SET
+			paused_at = NULL, resume_at = NULL, resumed_at = CASE WHEN paused_at IS NULL THEN resumed_at ELSE NOW() END
+		WHERE
+			id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ResumeDueWebsites resumes the paused websites whose resume time has passed and
+// returns how many were resumed.
+func (s *WebsiteStorage) ResumeDueWebsites(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE
+			website
+		SET
+			paused_at = NULL, resume_at = NULL, resumed_at = resume_at
+		WHERE
+			paused_at IS NOT NULL AND resume_at <= NOW()
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
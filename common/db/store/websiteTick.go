@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,9 +18,10 @@ import (
 type WebsiteStatus int
 
 var websiteStatusMap = map[string]WebsiteStatus{
-	"up":      Up,
-	"down":    Down,
-	"unknown": Unknown,
+	"up":          Up,
+	"down":        Down,
+	"unknown":     Unknown,
+	"maintenance": Maintenance,
 }
 
 func ParseWebsiteStatus(status string) (WebsiteStatus, error) {
@@ -36,6 +40,8 @@ func (s WebsiteStatus) String() string {
 		return "down"
 	case Unknown:
 		return "unknown"
+	case Maintenance:
+		return "maintenance"
 	}
 
 	return "unknown"
@@ -45,6 +51,7 @@ const (
 	Up WebsiteStatus = iota
 	Down
 	Unknown
+	Maintenance
 )
 
 type Tick struct {
@@ -63,25 +70,54 @@ type LatenciesMetrics struct {
 	P90 MetricData `json:"P90"`
 }
 
+// PhaseMetrics are the timings of a phase over a range. Aggregates keep no
+// histogram of the phases, so there are no percentiles.
+type PhaseMetrics struct {
+	Avg string `json:"avg"`
+}
+
 type Metrics struct {
 	Response     LatenciesMetrics `json:"response"`
 	Status       LatenciesMetrics `json:"status"`
 	Availability LatenciesMetrics `json:"availability"`
+	// Phase timings of the range by phase, without phases no tick measured
+	Phases map[string]PhaseMetrics `json:"phases"`
+}
+
+// Timings break the response time of an http check into phases. TTFB is the wait
+// between writing the request and the first response byte. Phases that didn't
+// happen, like dns on a reused connection, are nil.
+type Timings struct {
+	DNSMS      *int64 `json:"dnsMs,omitempty"`
+	ConnectMS  *int64 `json:"connectMs,omitempty"`
+	TLSMS      *int64 `json:"tlsMs,omitempty"`
+	TTFBMS     *int64 `json:"ttfbMs,omitempty"`
+	TransferMS *int64 `json:"transferMs,omitempty"`
 }
 
 type WebsiteTick struct {
-	ID             *string   `json:"id,omitempty"`
-	Time           time.Time `json:"time"`
-	ResponseTimeMS *int64    `json:"responseTime,omitempty"`
-	Status         string    `json:"status,omitempty"`
-	RegionID       *string   `json:"region_id,omitempty"`
-	WebsiteID      *string   `json:"website_id,omitempty"`
+	ID              *string      `json:"id,omitempty"`
+	Time            time.Time    `json:"time"`
+	ResponseTimeMS  *int64       `json:"responseTime,omitempty"`
+	Status          string       `json:"status,omitempty"`
+	RegionID        *string      `json:"region_id,omitempty"`
+	WebsiteID       *string      `json:"website_id,omitempty"`
+	FailedAssertion *string      `json:"failedAssertion,omitempty"`
+	CheckedAt       *time.Time   `json:"checkedAt,omitempty"`
+	Result          *CheckResult `json:"result,omitempty"`
+	ContentHash     *string      `json:"contentHash,omitempty"`
+	ContentSnippet  *string      `json:"contentSnippet,omitempty"`
+	HTTPStatusCode  *int         `json:"httpStatusCode,omitempty"`
+	ErrorKind       *string      `json:"errorKind,omitempty"`
+	ErrorMessage    *string      `json:"errorMessage,omitempty"`
+	Timings
 }
 
+// Uptime of a range, nil when it has no ticks outside maintenance.
 type Uptime struct {
-	Time            string `json:"time"`
-	Availability    string `json:"availability"`
-	AvgResponseTime string `json:"avg_response_time"`
+	Time            string  `json:"time"`
+	Availability    *string `json:"availability"`
+	AvgResponseTime *string `json:"avg_response_time"`
 }
 
 type WebsiteTickStorage struct {
@@ -90,7 +126,7 @@ type WebsiteTickStorage struct {
 
 func (s *WebsiteTickStorage) GetLatestStatus(ctx context.Context, websiteID string) ([]WebsiteTick, error) {
 	query := `
-		SELECT time, status
+		SELECT time, status, content_hash, content_snippet
 		FROM "website_tick"
 		WHERE website_id = $1
 		ORDER BY time DESC
@@ -117,7 +153,7 @@ func (s *WebsiteTickStorage) GetLatestStatus(ctx context.Context, websiteID stri
 		var tickTime pgtype.Timestamptz
 		var tick WebsiteTick
 
-		err := rows.Scan(&tickTime, &tick.Status)
+		err := rows.Scan(&tickTime, &tick.Status, &tick.ContentHash, &tick.ContentSnippet)
 		if err != nil {
 			return nil, err
 		}
@@ -132,55 +168,269 @@ func (s *WebsiteTickStorage) GetLatestStatus(ctx context.Context, websiteID stri
 	return status, nil
 }
 
-func (s *WebsiteTickStorage) BatchInsertTicks(ctx context.Context, ticks []WebsiteTick) error {
-	query := `
-		INSERT INTO "website_tick" (time, response_time_ms, status, region_id, website_id)
-		VALUES ($1, $2, $3, $4, $5)
-	`
-
+// BatchInsertTicks copies the ticks into a staging table and moves them into
+// website_tick, skipping ticks already stored so redelivered messages are
+// deduplicated. It returns the ticks that were inserted, as they were given.
+func (s *WebsiteTickStorage) BatchInsertTicks(ctx context.Context, ticks []WebsiteTick) ([]WebsiteTick, error) {
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	//nolint:errcheck
 	defer tx.Rollback(ctx)
 
-	for _, t := range ticks {
-		queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-		defer cancel()
+	stagingQuery := `
+		CREATE TEMP TABLE "website_tick_staging" (
+			time TIMESTAMPTZ,
+			response_time_ms INTEGER,
+			status TEXT,
+			region_id UUID,
+			website_id UUID,
+			failed_assertion TEXT,
+			checked_at TIMESTAMPTZ,
+			result JSONB,
+			content_hash TEXT,
+			content_snippet TEXT,
+			dns_ms INTEGER,
+			connect_ms INTEGER,
+			tls_ms INTEGER,
+			ttfb_ms INTEGER,
+			transfer_ms INTEGER,
+			http_status_code INTEGER,
+			error_kind TEXT,
+			error_message TEXT
+		) ON COMMIT DROP
+	`
+
+	queryCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.Exec(queryCtx, stagingQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	copyCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err = tx.CopyFrom(
+		copyCtx,
+		pgx.Identifier{"website_tick_staging"},
+		[]string{"time", "response_time_ms", "status", "region_id", "website_id", "failed_assertion", "checked_at", "result", "content_hash", "content_snippet", "dns_ms", "connect_ms", "tls_ms", "ttfb_ms", "transfer_ms", "http_status_code", "error_kind", "error_message"},
+		pgx.CopyFromSlice(len(ticks), func(i int) ([]any, error) {
+			t := ticks[i]
+			return []any{t.Time, t.ResponseTimeMS, t.Status, t.RegionID, t.WebsiteID, t.FailedAssertion, t.CheckedAt, t.Result, t.ContentHash, t.ContentSnippet, t.DNSMS, t.ConnectMS, t.TLSMS, t.TTFBMS, t.TransferMS, t.HTTPStatusCode, t.ErrorKind, t.ErrorMessage}, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Certificate chains are left out of the stored result, the latest chain of a
+	// website is kept in website_certificate. The inserted ticks are returned with
+	// them from the staging table.
+	insertQuery := `
+		WITH inserted AS (
+			INSERT INTO "website_tick" (time, response_time_ms, status, region_id, website_id, failed_assertion, checked_at, result, content_hash, content_snippet, dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, http_status_code, error_kind, error_message)
+			SELECT DISTINCT ON (website_id, region_id, time)
+				time, response_time_ms, status::website_status, region_id, website_id, failed_assertion, checked_at, NULLIF(result - 'certificates', '{}'::jsonb), content_hash, content_snippet, dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, http_status_code, error_kind, error_message
+			FROM "website_tick_staging"
+			ON CONFLICT (website_id, region_id, time) DO NOTHING
+			RETURNING website_id, region_id, time
+		)
+		SELECT DISTINCT ON (s.website_id, s.region_id, s.time)
+			s.time, s.response_time_ms, s.status, s.region_id, s.website_id, s.failed_assertion, s.checked_at, s.result, s.content_hash, s.content_snippet, s.dns_ms, s.connect_ms, s.tls_ms, s.ttfb_ms, s.transfer_ms, s.http_status_code, s.error_kind, s.error_message
+		FROM "website_tick_staging" s
+		JOIN inserted i USING (website_id, region_id, time)
+	`
+
+	insertCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-		_, err := tx.Exec(queryCtx, query, t.Time, t.ResponseTimeMS, t.Status, t.RegionID, t.WebsiteID)
+	rows, err := tx.Query(insertCtx, insertQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inserted []WebsiteTick = []WebsiteTick{}
+
+	for rows.Next() {
+		var t WebsiteTick
+
+		err := rows.Scan(
+			&t.Time,
+			&t.ResponseTimeMS,
+			&t.Status,
+			&t.RegionID,
+			&t.WebsiteID,
+			&t.FailedAssertion,
+			&t.CheckedAt,
+			&t.Result,
+			&t.ContentHash,
+			&t.ContentSnippet,
+			&t.DNSMS,
+			&t.ConnectMS,
+			&t.TLSMS,
+			&t.TTFBMS,
+			&t.TransferMS,
+			&t.HTTPStatusCode,
+			&t.ErrorKind,
+			&t.ErrorMessage,
+		)
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		inserted = append(inserted, t)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return inserted, nil
 }
 
-func (s *WebsiteTickStorage) GetTicks(ctx context.Context, websiteID string, days string, region string) ([]Tick, error) {
+// IsRejected reports whether the database rejected the data of a query, which
+// retrying won't fix, rather than failing to run it.
+func IsRejected(err error) bool {
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) {
+		return false
+	}
+
+	// Data exceptions and integrity constraint violations
+	return strings.HasPrefix(pgError.Code, "22") || strings.HasPrefix(pgError.Code, "23")
+}
+
+// GetRecentTicks returns the latest ticks of a website in a region as they were
+// stored, with the details of their checks such as the steps of multistep checks.
+func (s *WebsiteTickStorage) GetRecentTicks(ctx context.Context, websiteID string, region string, limit int) ([]WebsiteTick, error) {
 	query := `
-		SELECT
-			time_bucket('5 minutes', wt.time) as bucket,
-			AVG(wt.response_time_ms)::numeric::integer as avg_respones_times
+		SELECT ` + websiteTickColumns + `
 		FROM "website_tick" wt
 		JOIN "region" r ON wt.region_id = r.id
 		WHERE
 			wt.website_id = $1
-			AND wt.time BETWEEN NOW() - ($2::int * INTERVAL '1 day') AND NOW()
-			AND r.name = $3
-		GROUP BY bucket
-		ORDER BY bucket ASC
+			AND r.name = $2
+		ORDER BY wt.time DESC
+		LIMIT $3
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.Query(ctx, query, websiteID, days, region)
+	rows, err := s.db.Query(ctx, query, websiteID, region, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ticks []WebsiteTick = []WebsiteTick{}
+
+	for rows.Next() {
+		t, err := scanWebsiteTick(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ticks = append(ticks, *t)
+	}
+
+	return ticks, rows.Err()
+}
+
+// websiteTickColumns are the columns of a stored tick, as read by scanWebsiteTick.
+const websiteTickColumns = `
+	wt.time,
+	wt.response_time_ms,
+	wt.status,
+	wt.region_id,
+	wt.website_id,
+	wt.failed_assertion,
+	wt.checked_at,
+	wt.result,
+	wt.content_hash,
+	wt.content_snippet,
+	wt.dns_ms,
+	wt.connect_ms,
+	wt.tls_ms,
+	wt.ttfb_ms,
+	wt.transfer_ms,
+	wt.http_status_code,
+	wt.error_kind,
+	wt.error_message
+`
+
+func scanWebsiteTick(row pgx.Row) (*WebsiteTick, error) {
+	var t WebsiteTick
+
+	err := row.Scan(
+		&t.Time,
+		&t.ResponseTimeMS,
+		&t.Status,
+		&t.RegionID,
+		&t.WebsiteID,
+		&t.FailedAssertion,
+		&t.CheckedAt,
+		&t.Result,
+		&t.ContentHash,
+		&t.ContentSnippet,
+		&t.DNSMS,
+		&t.ConnectMS,
+		&t.TLSMS,
+		&t.TTFBMS,
+		&t.TransferMS,
+		&t.HTTPStatusCode,
+		&t.ErrorKind,
+		&t.ErrorMessage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetTicks returns the average response and phase times of the regions by bucket
+// over r, read from the coarsest source of the bucket size, ordered by region and
+// time.
+func (s *WebsiteTickStorage) GetTicks(ctx context.Context, websiteID string, r Range, bucket time.Duration, regions []string) ([]Tick, error) {
+	source, err := sourceFor(bucket, r.From)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			time_bucket($4::bigint * INTERVAL '1 second', a.bucket) AS tick_bucket,
+			r.name,
+			(SUM(a.response_time_sum)::numeric / NULLIF(SUM(a.response_time_count), 0))::integer,
+			(SUM(a.dns_sum)::numeric / NULLIF(SUM(a.dns_count), 0))::integer,
+			(SUM(a.connect_sum)::numeric / NULLIF(SUM(a.connect_count), 0))::integer,
+			(SUM(a.tls_sum)::numeric / NULLIF(SUM(a.tls_count), 0))::integer,
+			(SUM(a.ttfb_sum)::numeric / NULLIF(SUM(a.ttfb_count), 0))::integer,
+			(SUM(a.transfer_sum)::numeric / NULLIF(SUM(a.transfer_count), 0))::integer
+		FROM %s a
+		JOIN "region" r ON a.region_id = r.id
+		WHERE
+			a.website_id = $1
+			AND a.bucket BETWEEN $2 AND $3
+			AND r.name = ANY($5)
+		GROUP BY tick_bucket, r.name
+		ORDER BY r.name, tick_bucket ASC
+	`, source.view)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteID, r.From, r.To, int64(bucket.Seconds()), regions)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -197,7 +447,16 @@ func (s *WebsiteTickStorage) GetTicks(ctx context.Context, websiteID string, day
 		var tickTime pgtype.Timestamptz
 		var tick Tick
 
-		err := rows.Scan(&tickTime, &tick.ResponseTimeMS)
+		err := rows.Scan(
+			&tickTime,
+			&tick.Region.Name,
+			&tick.ResponseTimeMS,
+			&tick.DNSMS,
+			&tick.ConnectMS,
+			&tick.TLSMS,
+			&tick.TTFBMS,
+			&tick.TransferMS,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -210,117 +469,78 @@ func (s *WebsiteTickStorage) GetTicks(ctx context.Context, websiteID string, day
 	return ticks, nil
 }
 
-func (s *WebsiteTickStorage) GetMetrics(ctx context.Context, websiteID string, region string) (*Metrics, error) {
-	// Response times
-	response_time_query := `
-		WITH filtered_ticks AS (
-			SELECT
-				wt.response_time_ms,
-				wt.time
-			FROM website_tick wt
-			JOIN region r ON wt.region_id = r.id
-			WHERE
-				wt.website_id = $1
-				AND wt.time BETWEEN NOW() - INTERVAL '2 months' AND NOW()
-				AND r.name = $2
-		),
-		percentiles AS (
-			SELECT
-				percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time >= NOW() - INTERVAL '1 month') AS curr_p99,
-				percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time >= NOW() - INTERVAL '1 month') AS curr_p95,
-				percentile_cont(0.90) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time >= NOW() - INTERVAL '1 month') AS curr_p90,
-
-				percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time < NOW() - INTERVAL '1 month') AS prev_p99,
-				percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time < NOW() - INTERVAL '1 month') AS prev_p95,
-				percentile_cont(0.90) WITHIN GROUP (ORDER BY response_time_ms)
-					FILTER (WHERE time < NOW() - INTERVAL '1 month') AS prev_p90
-			FROM filtered_ticks
-		)
-		SELECT *
-		FROM (
-			SELECT
-				COALESCE(curr_p99::numeric(12,2), 0) AS p99,
-				COALESCE(curr_p95::numeric(12,2), 0) AS p95,
-				COALESCE(curr_p90::numeric(12,2), 0) AS p90
-			FROM percentiles
-			UNION ALL
-			SELECT
-				COALESCE(prev_p99::numeric(12,2), 0) AS p99,
-				COALESCE(prev_p95::numeric(12,2), 0) AS p95,
-				COALESCE(prev_p90::numeric(12,2), 0) AS p90
-			FROM percentiles
-		)
-	`
+// GetMetrics returns the metrics of a region over current, compared with the range
+// of the same length before it, from the finest aggregate still holding both.
+func (s *WebsiteTickStorage) GetMetrics(ctx context.Context, websiteID string, region string, current Range) (*Metrics, error) {
+	previous := Range{From: current.From.Add(-current.To.Sub(current.From)), To: current.From}
+	aggregate := aggregateRetaining(previous.From)
+
+	// Response times, estimated from the histogram of the aggregate
+	response_time_query := fmt.Sprintf(`
+		SELECT
+			%s,
+			%s
+		FROM %s a
+		JOIN region r ON a.region_id = r.id
+		WHERE
+			a.website_id = $1
+			AND a.bucket BETWEEN $3 AND $4
+			AND r.name = $2
+	`, histogramColumns("a.bucket >= $5"), histogramColumns("a.bucket < $5"), aggregate.view)
 
 	response_time_ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.Query(response_time_ctx, response_time_query, websiteID, region)
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return nil, err
-	}
-	defer rows.Close()
-
-	response_time_metric := LatenciesMetrics{}
+	currentTimes, previousTimes := newHistogram(), newHistogram()
 
-	if rows.Next() {
-		err := rows.Scan(&response_time_metric.P99.Current, &response_time_metric.P95.Current, &response_time_metric.P90.Current)
-		if err != nil {
-			return nil, err
-		}
+	err := s.db.QueryRow(response_time_ctx, response_time_query, websiteID, region, previous.From, current.To, current.From).Scan(append(currentTimes.targets(), previousTimes.targets()...)...)
+	if err != nil {
+		return nil, err
 	}
 
-	if rows.Next() {
-		err := rows.Scan(&response_time_metric.P99.Previous, &response_time_metric.P95.Previous, &response_time_metric.P90.Previous)
-		if err != nil {
-			return nil, err
-		}
+	response_time_metric := LatenciesMetrics{
+		P99: MetricData{Current: quantile(currentTimes, 0.99), Previous: quantile(previousTimes, 0.99)},
+		P95: MetricData{Current: quantile(currentTimes, 0.95), Previous: quantile(previousTimes, 0.95)},
+		P90: MetricData{Current: quantile(currentTimes, 0.90), Previous: quantile(previousTimes, 0.90)},
 	}
 
-	// Status
-	status_query := `
+	// Status. The p percentile of the up and down ticks of the range is down once
+	// at least p of them are down.
+	status_query := fmt.Sprintf(`
 		WITH status_data AS (
 			SELECT
-				CASE wt.status
-					WHEN 'up' THEN 1
-					WHEN 'down' THEN 0
-					ELSE NULL
-				END as status_numeric
-			FROM website_tick wt
-			JOIN "region" r ON wt.region_id = r.id
+				COALESCE(SUM(a.up), 0) AS up,
+				COALESCE(SUM(a.down), 0) AS down
+			FROM %s a
+			JOIN "region" r ON a.region_id = r.id
 			WHERE
-				website_id = $1
-				AND wt.time BETWEEN date_trunc('month', NOW()) AND date_trunc('month', NOW()) + INTERVAL '1 month'
+				a.website_id = $1
+				AND a.bucket BETWEEN $3 AND $4
 				AND r.name = $2
 		)
 		SELECT
-			CASE percentile_disc(0.99) WITHIN GROUP (ORDER BY status_numeric)
-				WHEN 1 THEN 'Up'
-				WHEN 0 THEN 'Down'
-				ELSE 'Unknown'
+			CASE
+				WHEN up + down = 0 THEN 'Unknown'
+				WHEN down >= 0.99 * (up + down) THEN 'Down'
+				ELSE 'Up'
 			END AS p99_status,
-			CASE percentile_disc(0.95) WITHIN GROUP (ORDER BY status_numeric)
-				WHEN 1 THEN 'Up'
-				WHEN 0 THEN 'Down'
-				ELSE 'Unknown'
+			CASE
+				WHEN up + down = 0 THEN 'Unknown'
+				WHEN down >= 0.95 * (up + down) THEN 'Down'
+				ELSE 'Up'
 			END AS p95_status,
-			CASE percentile_disc(0.90) WITHIN GROUP (ORDER BY status_numeric)
-				WHEN 1 THEN 'Up'
-				WHEN 0 THEN 'Down'
-				ELSE 'Unknown'
+			CASE
+				WHEN up + down = 0 THEN 'Unknown'
+				WHEN down >= 0.90 * (up + down) THEN 'Down'
+				ELSE 'Up'
 			END AS p90_status
 		FROM status_data
-	`
+	`, aggregate.view)
 
 	status_ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err = s.db.Query(status_ctx, status_query, websiteID, region)
+	rows, err := s.db.Query(status_ctx, status_query, websiteID, region, current.From, current.To)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, err
 	}
@@ -336,34 +556,33 @@ func (s *WebsiteTickStorage) GetMetrics(ctx context.Context, websiteID string, r
 	}
 
 	// Availability
-	availability_query := `
+	availability_query := fmt.Sprintf(`
 		WITH buckets AS (
 			SELECT
-				time_bucket('5 minutes', wt.time) AS bucket,
-				100.0 * AVG((wt.status = 'up')::int) AS availability_pct
-			FROM "website_tick" wt
-			JOIN "region" r ON wt.region_id = r.id
+				a.bucket,
+				100.0 * a.up / NULLIF(a.checks - a.maintenance, 0) AS availability_pct
+			FROM %s a
+			JOIN "region" r ON a.region_id = r.id
 			WHERE
-				wt.website_id = $1
-				AND wt.time BETWEEN NOW() - INTERVAL '2 month' AND NOW()
+				a.website_id = $1
+				AND a.bucket BETWEEN $3 AND $4
 				AND r.name = $2
-			GROUP BY bucket
 		),
 		percentiles AS (
 			SELECT
 				percentile_cont(0.99) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket >= NOW() - INTERVAL '1 month') AS curr_p99,
+					FILTER (WHERE bucket >= $5) AS curr_p99,
 				percentile_cont(0.95) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket >= NOW() - INTERVAL '1 month') AS curr_p95,
+					FILTER (WHERE bucket >= $5) AS curr_p95,
 				percentile_cont(0.90) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket >= NOW() - INTERVAL '1 month') AS curr_p90,
+					FILTER (WHERE bucket >= $5) AS curr_p90,
 
 				percentile_cont(0.99) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket < NOW() - INTERVAL '1 month') AS prev_p99,
+					FILTER (WHERE bucket < $5) AS prev_p99,
 				percentile_cont(0.95) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket < NOW() - INTERVAL '1 month') AS prev_p95,
+					FILTER (WHERE bucket < $5) AS prev_p95,
 				percentile_cont(0.90) WITHIN GROUP (ORDER BY availability_pct)
-					FILTER (WHERE bucket < NOW() - INTERVAL '1 month') AS prev_p90
+					FILTER (WHERE bucket < $5) AS prev_p90
 			FROM buckets
 		)
 		SELECT *
@@ -380,12 +599,12 @@ func (s *WebsiteTickStorage) GetMetrics(ctx context.Context, websiteID string, r
 				COALESCE(prev_p90::numeric(5,2), 0) AS p90
 			FROM percentiles
 		)
-	`
+	`, aggregate.view)
 
 	availability_ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err = s.db.Query(availability_ctx, availability_query, websiteID, region)
+	rows, err = s.db.Query(availability_ctx, availability_query, websiteID, region, previous.From, current.To, current.From)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, err
 	}
@@ -407,29 +626,90 @@ func (s *WebsiteTickStorage) GetMetrics(ctx context.Context, websiteID string, r
 		}
 	}
 
+	// Phase timings, averaged from the sums of the aggregate
+	phases_query := fmt.Sprintf(`
+		SELECT
+			p.phase,
+			(p.sum::numeric / p.count)::numeric(12,2) AS avg
+		FROM (
+			SELECT
+				SUM(a.dns_sum) AS dns_sum, SUM(a.dns_count) AS dns_count,
+				SUM(a.connect_sum) AS connect_sum, SUM(a.connect_count) AS connect_count,
+				SUM(a.tls_sum) AS tls_sum, SUM(a.tls_count) AS tls_count,
+				SUM(a.ttfb_sum) AS ttfb_sum, SUM(a.ttfb_count) AS ttfb_count,
+				SUM(a.transfer_sum) AS transfer_sum, SUM(a.transfer_count) AS transfer_count
+			FROM %s a
+			JOIN region r ON a.region_id = r.id
+			WHERE
+				a.website_id = $1
+				AND a.bucket BETWEEN $3 AND $4
+				AND r.name = $2
+		) t
+		CROSS JOIN LATERAL (
+			VALUES
+				('dns', t.dns_sum, t.dns_count),
+				('connect', t.connect_sum, t.connect_count),
+				('tls', t.tls_sum, t.tls_count),
+				('ttfb', t.ttfb_sum, t.ttfb_count),
+				('transfer', t.transfer_sum, t.transfer_count)
+		) AS p(phase, sum, count)
+		WHERE p.count > 0
+	`, aggregate.view)
+
+	phases_ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err = s.db.Query(phases_ctx, phases_query, websiteID, region, current.From, current.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	phases := map[string]PhaseMetrics{}
+
+	for rows.Next() {
+		var phase string
+		var m PhaseMetrics
+
+		err := rows.Scan(&phase, &m.Avg)
+		if err != nil {
+			return nil, err
+		}
+
+		phases[phase] = m
+	}
+
 	metrics := Metrics{
 		Response:     response_time_metric,
 		Status:       status_metric,
 		Availability: availability_metric,
+		Phases:       phases,
 	}
 
 	return &metrics, nil
 }
 
+// quantile formats the q quantile of a histogram like the numeric(12,2) metrics.
+func quantile(h Histogram, q float64) string {
+	return strconv.FormatFloat(h.Quantile(q), 'f', 2, 64)
+}
+
 type Range struct {
 	From time.Time
 	To   time.Time
 }
 
+// GetWebsiteUptime returns the availability and average response time of every
+// range, each read from the finest aggregate covering it that still holds its start.
 func (s *WebsiteTickStorage) GetWebsiteUptime(ctx context.Context, websiteID string, uptime_range []Range) ([]Uptime, error) {
 	query := `
 		SELECT
-			COALESCE(100.0 * (SUM(CASE WHEN wt.status = 'up' THEN 1 ELSE 0 END)::float / COUNT(*))::numeric(5,2), 0),
-			COALESCE(AVG(wt.response_time_ms)::numeric(12,2), 0)
-		FROM "website_tick" wt
+			(100.0 * SUM(a.up)::float / NULLIF(SUM(a.checks) - SUM(a.maintenance), 0))::numeric(5,2)::text,
+			(SUM(a.response_time_sum)::numeric / NULLIF(SUM(a.response_time_count), 0))::numeric(12,2)::text
+		FROM %s a
 		WHERE
-			wt.website_id = $1
-			AND wt.time BETWEEN $2 AND $3
+			a.website_id = $1
+			AND a.bucket BETWEEN $2 AND $3
 	`
 
 	var uptime []Uptime
@@ -442,16 +722,170 @@ func (s *WebsiteTickStorage) GetWebsiteUptime(ctx context.Context, websiteID str
 
 		u.Time = fmt.Sprintf("%v, %v", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
 
-		err := s.db.QueryRow(ctx, query, websiteID, r.From, r.To).Scan(&u.Availability, &u.AvgResponseTime)
+		source := aggregateFor(r.To.Sub(r.From))
+		if retaining := aggregateRetaining(r.From); retaining.bucket > source.bucket {
+			source = retaining
+		}
+
+		rangeQuery := fmt.Sprintf(query, source.view)
+
+		err := s.db.QueryRow(ctx, rangeQuery, websiteID, r.From, r.To).Scan(&u.Availability, &u.AvgResponseTime)
 		if err != nil {
 			return nil, err
 		}
 
-		u.Availability = fmt.Sprintf("%v%%", u.Availability)
-		u.AvgResponseTime = fmt.Sprintf("%v MS", u.AvgResponseTime)
+		if u.Availability != nil {
+			*u.Availability += "%"
+		}
+		if u.AvgResponseTime != nil {
+			*u.AvgResponseTime += " MS"
+		}
 
 		uptime = append(uptime, u)
 	}
 
 	return uptime, nil
 }
+
+type DailyUptime struct {
+	Date         string   `json:"date"`
+	Availability *float64 `json:"availability"`
+}
+
+// GetDailyUptimes returns one availability bar per day of each website, oldest
+// first. Days without ticks outside maintenance have a nil availability.
+func (s *WebsiteTickStorage) GetDailyUptimes(ctx context.Context, websiteIDs []string, days int) (map[string][]DailyUptime, error) {
+	query := fmt.Sprintf(`
+		WITH daily AS (
+			SELECT
+				a.website_id,
+				a.bucket,
+				(100.0 * (SUM(a.up)::float / NULLIF(SUM(a.checks) - SUM(a.maintenance), 0)))::numeric(5,2)::float AS availability
+			FROM %s a
+			WHERE
+				a.website_id = ANY($1::uuid[])
+				AND a.bucket >= date_trunc('day', NOW()) - (($2::int - 1) * INTERVAL '1 day')
+			GROUP BY a.website_id, a.bucket
+		)
+		SELECT
+			w.id::text,
+			d.day,
+			daily.availability
+		FROM unnest($1::uuid[]) AS w(id)
+		CROSS JOIN generate_series(
+			date_trunc('day', NOW()) - (($2::int - 1) * INTERVAL '1 day'),
+			date_trunc('day', NOW()),
+			INTERVAL '1 day'
+		) AS d(day)
+		LEFT JOIN daily ON daily.website_id = w.id AND daily.bucket = d.day
+		ORDER BY w.id, d.day ASC
+	`, dailyTicks.view)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteIDs, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uptimes := map[string][]DailyUptime{}
+
+	for rows.Next() {
+		var websiteID string
+		var day pgtype.Timestamptz
+		var u DailyUptime
+
+		err := rows.Scan(&websiteID, &day, &u.Availability)
+		if err != nil {
+			return nil, err
+		}
+
+		u.Date = day.Time.Format(time.DateOnly)
+
+		uptimes[websiteID] = append(uptimes[websiteID], u)
+	}
+
+	return uptimes, rows.Err()
+}
+
+// GetCurrentStatuses combines the latest tick of every region into one status per
+// website. Regions disagreeing makes a website "degraded", and it is in maintenance
+// while no region reports up or down. Websites without recent ticks are unknown.
+func (s *WebsiteTickStorage) GetCurrentStatuses(ctx context.Context, websiteIDs []string) (map[string]string, error) {
+	query := `
+		SELECT DISTINCT ON (website_id, region_id) website_id::text, status
+		FROM "website_tick"
+		WHERE
+			website_id = ANY($1::uuid[])
+			AND time >= NOW() - INTERVAL '1 day'
+		ORDER BY website_id, region_id, time DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, query, websiteIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type regionStatuses struct {
+		up, down, maintenance int
+	}
+	counts := map[string]*regionStatuses{}
+
+	for rows.Next() {
+		var websiteID, status string
+
+		if err := rows.Scan(&websiteID, &status); err != nil {
+			return nil, err
+		}
+
+		c, ok := counts[websiteID]
+		if !ok {
+			c = &regionStatuses{}
+			counts[websiteID] = c
+		}
+
+		switch status {
+		case Up.String():
+			c.up++
+		case Down.String():
+			c.down++
+		case Maintenance.String():
+			c.maintenance++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]string{}
+
+	for _, id := range websiteIDs {
+		c, ok := counts[id]
+		if !ok {
+			statuses[id] = Unknown.String()
+			continue
+		}
+
+		switch {
+		case c.up > 0 && c.down == 0:
+			statuses[id] = Up.String()
+		case c.down > 0 && c.up == 0:
+			statuses[id] = Down.String()
+		case c.up > 0 && c.down > 0:
+			statuses[id] = "degraded"
+		case c.maintenance > 0:
+			statuses[id] = Maintenance.String()
+		default:
+			statuses[id] = Unknown.String()
+		}
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB connects to the migrated database of TEST_DATABASE_URL, skipping the test
+// without one.
+func testDB(tb testing.TB) *pgxpool.Pool {
+	tb.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		tb.Fatalf("connecting to database: %v", err)
+	}
+	tb.Cleanup(db.Close)
+
+	return db
+}
+
+// testWebsite creates a website with a region and returns their ids. Everything is
+// deleted when the test ends.
+func testWebsite(tb testing.TB, db *pgxpool.Pool) (string, string) {
+	tb.Helper()
+
+	ctx := context.Background()
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	var userID, organizationID, websiteID, regionID string
+
+	err := db.QueryRow(ctx, `
+		WITH u AS (
+			INSERT INTO "user" (name, email) VALUES ('test', $1) RETURNING id
+		), o AS (
+			INSERT INTO "organization" (name, created_by) SELECT 'test', id FROM u RETURNING id, created_by
+		), w AS (
+			INSERT INTO "website" (url, created_by, organization_id) SELECT 'https://example.com', created_by, id FROM o RETURNING id
+		), r AS (
+			INSERT INTO "region" (name) VALUES ($2) RETURNING id
+		)
+		SELECT (SELECT id FROM u), (SELECT id FROM o), (SELECT id FROM w), (SELECT id FROM r)
+	`, "test-"+suffix+"@example.com", "test-"+suffix).Scan(&userID, &organizationID, &websiteID, &regionID)
+	if err != nil {
+		tb.Fatalf("creating website: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM "website_tick" WHERE website_id = $1`,
+			`DELETE FROM "website" WHERE id = $1`,
+		} {
+			if _, err := db.Exec(ctx, query, websiteID); err != nil {
+				tb.Errorf("cleaning up website: %v", err)
+			}
+		}
+
+		db.Exec(ctx, `DELETE FROM "region" WHERE id = $1`, regionID)             //nolint:errcheck
+		db.Exec(ctx, `DELETE FROM "organization" WHERE id = $1`, organizationID) //nolint:errcheck
+		db.Exec(ctx, `DELETE FROM "user" WHERE id = $1`, userID)                 //nolint:errcheck
+	})
+
+	return websiteID, regionID
+}
+
+// testTicks returns n up ticks a second apart from start.
+func testTicks(websiteID string, regionID string, start time.Time, n int) []WebsiteTick {
+	ticks := make([]WebsiteTick, n)
+
+	for i := range ticks {
+		responseTime := int64(100 + i%50)
+		ticks[i] = WebsiteTick{
+			Time:           start.Add(time.Duration(i) * time.Second),
+			ResponseTimeMS: &responseTime,
+			Status:         Up.String(),
+			RegionID:       &regionID,
+			WebsiteID:      &websiteID,
+		}
+	}
+
+	return ticks
+}
+
+func TestBatchInsertTicksDeduplicatesRedeliveries(t *testing.T) {
+	db := testDB(t)
+	s := WebsiteTickStorage{db}
+	ctx := context.Background()
+
+	websiteID, regionID := testWebsite(t, db)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	ticks := testTicks(websiteID, regionID, start, 10)
+
+	inserted, err := s.BatchInsertTicks(ctx, ticks)
+	if err != nil {
+		t.Fatalf("first insert: %v", err)
+	}
+	if len(inserted) != 10 {
+		t.Fatalf("first insert stored %d ticks, want 10", len(inserted))
+	}
+
+	// Half of the batch redelivered with five new ticks
+	redelivered := append(testTicks(websiteID, regionID, start, 10)[5:], testTicks(websiteID, regionID, start.Add(10*time.Second), 5)...)
+
+	inserted, err = s.BatchInsertTicks(ctx, redelivered)
+	if err != nil {
+		t.Fatalf("redelivered insert: %v", err)
+	}
+	if len(inserted) != 5 {
+		t.Fatalf("redelivered insert stored %d ticks, want the 5 new ones", len(inserted))
+	}
+	for _, tick := range inserted {
+		if tick.Time.Before(start.Add(10 * time.Second)) {
+			t.Errorf("tick at %v stored again", tick.Time)
+		}
+	}
+
+	// The same tick twice in one batch
+	twice := testTicks(websiteID, regionID, start.Add(time.Minute), 1)
+	twice = append(twice, twice[0])
+
+	inserted, err = s.BatchInsertTicks(ctx, twice)
+	if err != nil {
+		t.Fatalf("duplicate insert: %v", err)
+	}
+	if len(inserted) != 1 {
+		t.Fatalf("duplicate insert stored %d ticks, want 1", len(inserted))
+	}
+
+	var stored int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM "website_tick" WHERE website_id = $1`, websiteID).Scan(&stored); err != nil {
+		t.Fatalf("counting ticks: %v", err)
+	}
+	if stored != 16 {
+		t.Fatalf("%d ticks stored, want 16", stored)
+	}
+}
+
+func TestBatchInsertTicksLeavesOutCertificates(t *testing.T) {
+	db := testDB(t)
+	s := WebsiteTickStorage{db}
+	ctx := context.Background()
+
+	websiteID, regionID := testWebsite(t, db)
+
+	ticks := testTicks(websiteID, regionID, time.Now().Add(-time.Hour).Truncate(time.Second), 2)
+	ticks[0].Result = &CheckResult{Certificates: []Certificate{{Subject: "CN=example.com"}}}
+	ticks[1].Result = &CheckResult{
+		TLS:          &TLSResult{Version: "TLS 1.3"},
+		Certificates: []Certificate{{Subject: "CN=example.com"}},
+	}
+
+	inserted, err := s.BatchInsertTicks(ctx, ticks)
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	for _, tick := range inserted {
+		if tick.Result == nil || len(tick.Result.Certificates) != 1 {
+			t.Errorf("tick at %v returned without its certificates: %+v", tick.Time, tick.Result)
+		}
+	}
+
+	var withCertificates, withResult int
+	err = db.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE result ? 'certificates'), COUNT(result)
+		FROM "website_tick" WHERE website_id = $1
+	`, websiteID).Scan(&withCertificates, &withResult)
+	if err != nil {
+		t.Fatalf("reading ticks: %v", err)
+	}
+	if withCertificates != 0 {
+		t.Errorf("%d ticks stored with certificates, want 0", withCertificates)
+	}
+	if withResult != 1 {
+		t.Errorf("%d ticks stored with a result, want the one with tls details", withResult)
+	}
+}
+
+func TestBatchInsertTicksRejectsInvalidTicks(t *testing.T) {
+	db := testDB(t)
+	s := WebsiteTickStorage{db}
+
+	websiteID, regionID := testWebsite(t, db)
+
+	ticks := testTicks(websiteID, regionID, time.Now().Add(-time.Hour), 3)
+	ticks[1].Status = "sideways"
+
+	_, err := s.BatchInsertTicks(context.Background(), ticks)
+	if err == nil {
+		t.Fatal("invalid status inserted")
+	}
+	if !IsRejected(err) {
+		t.Fatalf("invalid status not reported as rejected: %v", err)
+	}
+}
+
+func BenchmarkBatchInsertTicks(b *testing.B) {
+	const batchSize = 10000
+
+	db := testDB(b)
+	s := WebsiteTickStorage{db}
+	ctx := context.Background()
+
+	websiteID, regionID := testWebsite(b, db)
+	start := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ticks := testTicks(websiteID, regionID, start.Add(time.Duration(i*batchSize)*time.Second), batchSize)
+		b.StartTimer()
+
+		inserted, err := s.BatchInsertTicks(ctx, ticks)
+		if err != nil {
+			b.Fatalf("insert: %v", err)
+		}
+		if len(inserted) != batchSize {
+			b.Fatalf("stored %d ticks, want %d", len(inserted), batchSize)
+		}
+	}
+
+	b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "ticks/s")
+}
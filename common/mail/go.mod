@@ -0,0 +1,9 @@
+module github.com/DevanshBhavsar3/echo/common/mail
+
+go 1.24.4
+
+require github.com/DevanshBhavsar3/echo/common/config v0.0.0-00010101000000-000000000000
+
+require github.com/joho/godotenv v1.5.1 // indirect
+
+replace github.com/DevanshBhavsar3/echo/common/config => ../config
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/DevanshBhavsar3/echo/common/config"
+)
+
+// Send sends a plain text mail through the SMTP server in the environment.
+func Send(ctx context.Context, to []string, subject string, body string) error {
+	host := config.Get("SMTP_HOST")
+	port := config.Get("SMTP_PORT")
+	username := config.Get("SMTP_USERNAME")
+	password := config.Get("SMTP_PASSWORD")
+	from := config.Get("SMTP_FROM")
+
+	if host == "" || from == "" {
+		return ErrSMTPNotConfigured
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from,
+		strings.Join(to, ", "),
+		subject,
+		body,
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(host, port), auth, from, to, []byte(msg))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+var (
+	ErrSMTPNotConfigured = errors.New("smtp server is not configured")
+)
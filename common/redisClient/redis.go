@@ -1,8 +1,12 @@
 package redisClient
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,13 +20,25 @@ type RedisClient struct {
 }
 
 type RedisPayload struct {
-	ID         string `json:"id"`
-	Url        string `json:"url"`
-	RegionName string `json:"regionName"`
+	ID          string          `json:"id"`
+	Url         string          `json:"url"`
+	RegionName  string          `json:"regionName"`
+	CheckType   string          `json:"checkType,omitempty"`
+	Check       json.RawMessage `json:"check,omitempty"`
+	ScheduledAt time.Time       `json:"scheduledAt,omitzero"`
 }
 
+// WebsiteStream carried the checks of every region before checks were routed to
+// per region streams. It is still read while old publishers are rolled out.
 var WebsiteStream = "echo:websites"
 var DatabaseStream = "echo:ticks"
+var WebsiteDeadLetterStream = "echo:websites:dlq"
+var DatabaseDeadLetterStream = "echo:ticks:dlq"
+
+// RegionStream is the stream holding the checks of a single region.
+func RegionStream(region string) string {
+	return WebsiteStream + ":" + region
+}
 
 func NewRedisClient(ctx context.Context) RedisClient {
 	client := redis.NewClient(&redis.Options{
@@ -92,8 +108,18 @@ func (r RedisClient) XGroupCreate(ctx context.Context, stream string, region str
 }
 
 func (r RedisClient) XReadGroup(ctx context.Context, stream string, group string, consumer string) []redis.XStream {
+	return r.XReadGroupStreams(ctx, []string{stream}, group, consumer)
+}
+
+// XReadGroupStreams reads new messages from several streams the group exists on.
+func (r RedisClient) XReadGroupStreams(ctx context.Context, streams []string, group string, consumer string) []redis.XStream {
+	args := append([]string{}, streams...)
+	for range streams {
+		args = append(args, ">")
+	}
+
 	res, err := r.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
-		Streams:  []string{stream, ">"},
+		Streams:  args,
 		Group:    group,
 		Consumer: consumer,
 		Count:    10,
@@ -119,3 +145,156 @@ func (r RedisClient) XAck(ctx context.Context, stream string, group string, ids
 		log.Printf("error acknowledging messages:\n%v", err)
 	}
 }
+
+// XAutoClaim takes over the messages of a group that have been pending for longer
+// than minIdle, including the ones of crashed consumers.
+func (r RedisClient) XAutoClaim(ctx context.Context, stream string, group string, consumer string, minIdle time.Duration) []redis.XMessage {
+	var claimed []redis.XMessage
+	start := "0-0"
+
+	for {
+		msgs, next, err := r.Client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  minIdle,
+			Start:    start,
+			Count:    100,
+		}).Result()
+
+		if err != nil {
+			log.Printf("failed to claim pending messages:\n%v", err)
+			return claimed
+		}
+
+		claimed = append(claimed, msgs...)
+
+		if next == "0-0" || len(msgs) == 0 {
+			return claimed
+		}
+		start = next
+	}
+}
+
+// PendingPageSize is the most pending entries read by one XPENDING call.
+var PendingPageSize int64 = 1000
+
+// DeliveryCounts returns how many times each pending message of a consumer was
+// delivered. Other pending messages can sit between the ids, so the pending
+// entries are paged through until every id is found.
+func (r RedisClient) DeliveryCounts(ctx context.Context, stream string, group string, consumer string, ids ...string) map[string]int64 {
+	counts := map[string]int64{}
+
+	if len(ids) == 0 {
+		return counts
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	sorted := slices.Clone(ids)
+	slices.SortFunc(sorted, CompareIDs)
+	start, end := sorted[0], sorted[len(sorted)-1]
+
+	for len(wanted) > 0 {
+		pending, err := r.Client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			Start:    start,
+			End:      end,
+			Count:    PendingPageSize,
+		}).Result()
+
+		if err != nil {
+			log.Printf("failed to get pending messages:\n%v", err)
+			return counts
+		}
+
+		for _, p := range pending {
+			if wanted[p.ID] {
+				counts[p.ID] = p.RetryCount
+				delete(wanted, p.ID)
+			}
+		}
+
+		if int64(len(pending)) < PendingPageSize {
+			break
+		}
+
+		// Exclusive start, the next page begins after the last entry
+		start = "(" + pending[len(pending)-1].ID
+	}
+
+	return counts
+}
+
+// CompareIDs orders stream ids like "1700000000000-3" by time, then sequence.
+func CompareIDs(a, b string) int {
+	aTime, aSeq, _ := strings.Cut(a, "-")
+	bTime, bSeq, _ := strings.Cut(b, "-")
+
+	if c := cmp.Compare(parseIDPart(aTime), parseIDPart(bTime)); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(parseIDPart(aSeq), parseIDPart(bSeq))
+}
+
+func parseIDPart(part string) uint64 {
+	n, _ := strconv.ParseUint(part, 10, 64)
+	return n
+}
+
+// DeadLetter copies a message of source to a dead letter stream with the reason it was dropped.
+func (r RedisClient) DeadLetter(ctx context.Context, stream string, source string, msg redis.XMessage, reason string) error {
+	_, err := r.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]any{
+			"data":     msg.Values["data"],
+			"id":       msg.ID,
+			"stream":   source,
+			"reason":   reason,
+			"failedAt": time.Now().Format(time.RFC3339),
+		},
+	}).Result()
+
+	if err != nil {
+		log.Printf("failed to add message to dead letter stream:\n%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeadLetters returns the latest messages of a dead letter stream, newest first.
+func (r RedisClient) DeadLetters(ctx context.Context, stream string, count int64) ([]redis.XMessage, error) {
+	return r.Client.XRevRangeN(ctx, stream, "+", "-", count).Result()
+}
+
+// Replay adds a dead lettered message back to the stream it came from, or to stream
+// when that is unknown, and removes it from the dead letter stream. It returns
+// redis.Nil when the message does not exist.
+func (r RedisClient) Replay(ctx context.Context, deadLetterStream string, stream string, id string) error {
+	msgs, err := r.Client.XRangeN(ctx, deadLetterStream, id, id, 1).Result()
+	if err != nil {
+		return err
+	}
+
+	if len(msgs) == 0 {
+		return redis.Nil
+	}
+
+	if source, ok := msgs[0].Values["stream"].(string); ok && source != "" {
+		stream = source
+	}
+
+	err = r.XAdd(ctx, stream, msgs[0].Values["data"])
+	if err != nil {
+		return err
+	}
+
+	return r.Client.XDel(ctx, deadLetterStream, id).Err()
+}
@@ -0,0 +1,16 @@
+package redisClient
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareIDs(t *testing.T) {
+	ids := []string{"1700000000010-0", "999-5", "1700000000002-11", "1700000000002-2", "1700000000002-0"}
+	slices.SortFunc(ids, CompareIDs)
+
+	want := []string{"999-5", "1700000000002-0", "1700000000002-2", "1700000000002-11", "1700000000010-0"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("sorted ids = %v, want %v", ids, want)
+	}
+}
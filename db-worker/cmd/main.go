@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"log"
+	"os"
 	"time"
 
+	"github.com/DevanshBhavsar3/echo/common/config"
 	"github.com/DevanshBhavsar3/echo/common/db"
 	"github.com/DevanshBhavsar3/echo/common/db/store"
 	"github.com/DevanshBhavsar3/echo/common/redisClient"
@@ -13,6 +16,16 @@ import (
 var (
 	BATCH_SIZE    = 100
 	BATCH_TIMEOUT = time.Second * 5
+
+	INCIDENT_FAILURE_THRESHOLD = config.GetInt("INCIDENT_FAILURE_THRESHOLD", 3)
+	INCIDENT_REGION_QUORUM     = config.GetInt("INCIDENT_REGION_QUORUM", 1)
+
+	SLO_EVALUATION_INTERVAL = config.GetDuration("SLO_EVALUATION_INTERVAL", time.Minute)
+
+	WEBSITE_PURGE_INTERVAL = config.GetDuration("WEBSITE_PURGE_INTERVAL", time.Hour)
+	WEBSITE_EXPORT_DIR     = config.Get("WEBSITE_EXPORT_DIR")
+
+	DB_WORKER_ID = config.Get("DB_WORKER_ID")
 )
 
 func main() {
@@ -26,23 +39,61 @@ func main() {
 
 	rclient := redisClient.NewRedisClient(ctx)
 
-	var ticks []store.WebsiteTick
+	tracker, err := internal.NewIncidentTracker(ctx, storage, INCIDENT_FAILURE_THRESHOLD, INCIDENT_REGION_QUORUM)
+	if err != nil {
+		log.Fatalf("failed to load open incidents:\n%v", err)
+	}
+
+	dispatcher := internal.NewDispatcher(storage)
+
+	if DB_WORKER_ID == "" {
+		DB_WORKER_ID, _ = os.Hostname()
+	}
+
+	// Create consumer group
+	rclient.XGroupCreate(ctx, redisClient.DatabaseStream, internal.ConsumerGroup)
+
+	var batch internal.Batch
+
+	// Pick up what a previous run left unacknowledged
+	internal.Reclaim(ctx, rclient, DB_WORKER_ID, &batch)
 
 	ticker := time.NewTicker(BATCH_TIMEOUT)
 	defer ticker.Stop()
 
+	reclaimTicker := time.NewTicker(internal.ReclaimIdle)
+	defer reclaimTicker.Stop()
+
+	if WEBSITE_EXPORT_DIR == "" {
+		WEBSITE_EXPORT_DIR = "exports"
+	}
+
+	// SLO evaluation and purging have their own context so they stop with the worker
+	// but never hold up ingestion
+	sloCtx, stopSLOs := context.WithCancel(ctx)
+	defer stopSLOs()
+
+	go internal.RunSLOEvaluator(sloCtx, storage, dispatcher, SLO_EVALUATION_INTERVAL)
+
+	purgeCtx, stopPurge := context.WithCancel(ctx)
+	defer stopPurge()
+
+	go internal.RunPurger(purgeCtx, storage, WEBSITE_EXPORT_DIR, WEBSITE_PURGE_INTERVAL)
+
 	for {
 		select {
 		case <-ticker.C:
-			if len(ticks) > 0 {
-				internal.ProcessBatch(ctx, storage, &ticks)
+			if batch.Len() > 0 {
+				internal.ProcessBatch(ctx, storage, rclient, tracker, dispatcher, &batch)
 			}
+		case <-reclaimTicker.C:
+			internal.Reclaim(ctx, rclient, DB_WORKER_ID, &batch)
 		default:
-			res := rclient.XRead(ctx, redisClient.DatabaseStream)
-			internal.AddToBatch(res, &ticks)
+			res := rclient.XReadGroup(ctx, redisClient.DatabaseStream, internal.ConsumerGroup, DB_WORKER_ID)
+			internal.AddToBatch(ctx, rclient, res, &batch)
 
-			if len(ticks) > BATCH_SIZE {
-				internal.ProcessBatch(ctx, storage, &ticks)
+			if batch.Len() > BATCH_SIZE {
+				internal.ProcessBatch(ctx, storage, rclient, tracker, dispatcher, &batch)
 			}
 		}
 	}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+const (
+	CertificateExpiring = "certificate.expiring"
+)
+
+// TrackCertificates stores the latest certificate chain of every website in the
+// ticks and warns once about certificates close to their expiry.
+func TrackCertificates(ctx context.Context, storage store.Storage, dispatcher *Dispatcher, ticks []store.WebsiteTick) {
+	latest := map[string]store.WebsiteTick{}
+
+	for _, tick := range ticks {
+		if tick.WebsiteID == nil || tick.Result == nil || len(tick.Result.Certificates) == 0 {
+			continue
+		}
+
+		if last, ok := latest[*tick.WebsiteID]; ok && !checkedAt(last).Before(checkedAt(tick)) {
+			continue
+		}
+
+		latest[*tick.WebsiteID] = tick
+	}
+
+	for websiteID, tick := range latest {
+		err := storage.Certificate.UpsertCertificate(ctx, websiteID, tick.Result.Certificates, checkedAt(tick))
+		if err != nil {
+			log.Printf("error storing certificate of website %s:\n%v", websiteID, err)
+			continue
+		}
+
+		cert, err := storage.Certificate.WarnCertificate(ctx, websiteID)
+		if err != nil {
+			if !errors.Is(err, store.ErrNotFound) {
+				log.Printf("error checking certificate expiry of website %s:\n%v", websiteID, err)
+			}
+			continue
+		}
+
+		log.Printf("Certificate of website %s expires at %s", websiteID, cert.NotAfter)
+		dispatcher.Send(ctx, CertificateExpiring, websiteID, certificateMessage(*cert))
+	}
+}
+
+func checkedAt(tick store.WebsiteTick) time.Time {
+	if tick.CheckedAt != nil {
+		return *tick.CheckedAt
+	}
+
+	return tick.Time
+}
+
+func certificateMessage(cert store.WebsiteCertificate) string {
+	if !time.Now().Before(cert.NotAfter) {
+		return fmt.Sprintf("Certificate %s expired on %s.", cert.Subject, cert.NotAfter.Format(time.RFC1123))
+	}
+
+	return fmt.Sprintf("Certificate %s expires in %d days, on %s.", cert.Subject, cert.DaysUntilExpiry(time.Now()), cert.NotAfter.Format(time.RFC1123))
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+const (
+	ContentChanged = "content.changed"
+)
+
+// TrackContent notifies when the content hash of a website differs from the last
+// one seen in any region.
+func TrackContent(ctx context.Context, storage store.Storage, dispatcher *Dispatcher, ticks []store.WebsiteTick) {
+	var hashed []store.WebsiteTick
+
+	for _, tick := range ticks {
+		if tick.WebsiteID == nil || tick.ContentHash == nil {
+			continue
+		}
+
+		hashed = append(hashed, tick)
+	}
+
+	// Hashes older than the stored one are ignored, so store them in order
+	slices.SortFunc(hashed, func(a, b store.WebsiteTick) int {
+		return checkedAt(a).Compare(checkedAt(b))
+	})
+
+	for _, tick := range hashed {
+		websiteID := *tick.WebsiteID
+
+		previous, err := storage.Website.UpdateContentHash(ctx, websiteID, *tick.ContentHash, checkedAt(tick))
+		if err != nil {
+			log.Printf("error storing content hash of website %s:\n%v", websiteID, err)
+			continue
+		}
+
+		// The first hash of a website has nothing to compare with
+		if previous == nil || *previous == *tick.ContentHash {
+			continue
+		}
+
+		log.Printf("Content of website %s changed", websiteID)
+		dispatcher.Send(ctx, ContentChanged, websiteID, contentMessage(tick))
+	}
+}
+
+func contentMessage(tick store.WebsiteTick) string {
+	if tick.ContentSnippet == nil || *tick.ContentSnippet == "" {
+		return "Content changed."
+	}
+
+	return fmt.Sprintf("Content changed, now reads: %s", *tick.ContentSnippet)
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+const (
+	DNSDrift         = "dns.drift"
+	DNSDriftResolved = "dns.drift_resolved"
+)
+
+// TrackDNS records the dns snapshots of the ticks and notifies when the records of
+// a region start or stop drifting from the pinned ones.
+func TrackDNS(ctx context.Context, storage store.Storage, dispatcher *Dispatcher, ticks []store.WebsiteTick) {
+	var snapshots []store.WebsiteTick
+
+	for _, tick := range ticks {
+		if tick.WebsiteID == nil || tick.RegionID == nil || tick.Result == nil || len(tick.Result.Records) == 0 {
+			continue
+		}
+
+		snapshots = append(snapshots, tick)
+	}
+
+	// Older snapshots than the latest stored are ignored, so record them in order
+	slices.SortFunc(snapshots, func(a, b store.WebsiteTick) int {
+		return checkedAt(a).Compare(checkedAt(b))
+	})
+
+	for _, tick := range snapshots {
+		websiteID := *tick.WebsiteID
+
+		snapshot, previous, err := storage.DNS.RecordSnapshot(ctx, websiteID, *tick.RegionID, tick.Result.Records, checkedAt(tick))
+		if err != nil {
+			log.Printf("error recording dns snapshot of website %s:\n%v", websiteID, err)
+			continue
+		}
+
+		if snapshot == nil {
+			continue
+		}
+
+		log.Printf("DNS records of website %s changed in region %s", websiteID, *tick.RegionID)
+
+		wasDrifting := previous != nil && len(previous.Drift) > 0
+
+		switch {
+		case len(snapshot.Drift) > 0 && !wasDrifting:
+			dispatcher.Send(ctx, DNSDrift, websiteID, fmt.Sprintf("DNS records drifted from the pinned ones: %s.", strings.Join(snapshot.Drift, "; ")))
+		case len(snapshot.Drift) == 0 && wasDrifting:
+			dispatcher.Send(ctx, DNSDriftResolved, websiteID, "DNS records match the pinned ones again.")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+const (
+	IncidentOpened   = "incident.opened"
+	IncidentResolved = "incident.resolved"
+)
+
+type IncidentEvent struct {
+	Type     string
+	Incident store.Incident
+}
+
+// IncidentTracker turns the tick stream into incidents. A region is failing once it
+// reports Threshold consecutive down ticks, and an incident opens once Quorum regions
+// of a website are failing at the same time.
+type IncidentTracker struct {
+	storage   store.Storage
+	threshold int
+	quorum    int
+
+	// website id -> region id -> consecutive down ticks
+	failures map[string]map[string]int
+	// website id -> number of failing regions in the open incident
+	open map[string]int
+	// website id -> time of the first down tick of the current run
+	since map[string]time.Time
+}
+
+func NewIncidentTracker(ctx context.Context, storage store.Storage, threshold int, quorum int) (*IncidentTracker, error) {
+	incidents, err := storage.Incident.GetOpenIncidents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &IncidentTracker{
+		storage:   storage,
+		threshold: max(threshold, 1),
+		quorum:    max(quorum, 1),
+		failures:  map[string]map[string]int{},
+		open:      map[string]int{},
+		since:     map[string]time.Time{},
+	}
+
+	for _, i := range incidents {
+		t.open[i.WebsiteID] = len(i.AffectedRegions)
+	}
+
+	return t, nil
+}
+
+// Process updates the failure counters with a batch of ticks and opens or resolves
+// incidents for the websites it touched.
+func (t *IncidentTracker) Process(ctx context.Context, ticks []store.WebsiteTick) []IncidentEvent {
+	touched := map[string]time.Time{}
+
+	for _, tick := range ticks {
+		if tick.WebsiteID == nil || tick.RegionID == nil {
+			continue
+		}
+
+		websiteID, regionID := *tick.WebsiteID, *tick.RegionID
+
+		regions, ok := t.failures[websiteID]
+		if !ok {
+			regions = map[string]int{}
+			t.failures[websiteID] = regions
+		}
+
+		switch tick.Status {
+		case store.Down.String():
+			if _, ok := t.since[websiteID]; !ok {
+				t.since[websiteID] = tick.Time
+			}
+			regions[regionID]++
+		case store.Up.String():
+			regions[regionID] = 0
+		}
+
+		touched[websiteID] = tick.Time
+	}
+
+	var events []IncidentEvent
+
+	for websiteID, lastTick := range touched {
+		var failing []string
+		for regionID, count := range t.failures[websiteID] {
+			if count >= t.threshold {
+				failing = append(failing, regionID)
+			}
+		}
+
+		openRegions, isOpen := t.open[websiteID]
+
+		// An incident resolved by hand while the website is still failing is only
+		// seen in the store
+		if isOpen && len(failing) >= t.quorum && len(failing) <= openRegions {
+			resolved, err := t.resolvedManually(ctx, websiteID)
+			if err != nil {
+				log.Printf("error getting incident of website %s:\n%v", websiteID, err)
+				continue
+			}
+
+			isOpen = !resolved
+		}
+
+		switch {
+		case len(failing) >= t.quorum && (!isOpen || len(failing) > openRegions):
+			incident, created, err := t.storage.Incident.UpsertOpenIncident(ctx, websiteID, failing, t.since[websiteID])
+			if err != nil {
+				log.Printf("error opening incident for website %s:\n%v", websiteID, err)
+				continue
+			}
+
+			t.open[websiteID] = len(failing)
+
+			if created {
+				log.Printf("Opened incident %s for website %s", incident.ID, websiteID)
+				events = append(events, IncidentEvent{Type: IncidentOpened, Incident: *incident})
+			}
+		case len(failing) < t.quorum && isOpen:
+			incident, err := t.storage.Incident.ResolveOpenIncident(ctx, websiteID, lastTick)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				log.Printf("error resolving incident for website %s:\n%v", websiteID, err)
+				continue
+			}
+
+			delete(t.open, websiteID)
+
+			if incident != nil {
+				log.Printf("Resolved incident %s for website %s", incident.ID, websiteID)
+				events = append(events, IncidentEvent{Type: IncidentResolved, Incident: *incident})
+			}
+		}
+
+		// Forget the start of the run once every region has recovered
+		if len(failing) == 0 && !hasDownTicks(t.failures[websiteID]) {
+			delete(t.since, websiteID)
+		}
+	}
+
+	return events
+}
+
+// resolvedManually reports whether the incident the tracker holds open for a
+// website was resolved outside of it. The failing run then starts over at the
+// resolution, so a new incident doesn't overlap the resolved one.
+func (t *IncidentTracker) resolvedManually(ctx context.Context, websiteID string) (bool, error) {
+	incident, err := t.storage.Incident.GetLatestIncident(ctx, websiteID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return false, err
+	}
+
+	if incident != nil && incident.ResolvedAt == nil {
+		return false, nil
+	}
+
+	delete(t.open, websiteID)
+
+	if incident != nil && t.since[websiteID].Before(*incident.ResolvedAt) {
+		t.since[websiteID] = *incident.ResolvedAt
+	}
+
+	return true, nil
+}
+
+func hasDownTicks(regions map[string]int) bool {
+	for _, count := range regions {
+		if count > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// ApplyMaintenance marks the ticks checked during a maintenance window of their
+// website as maintenance, so they count neither as downtime nor towards incidents.
+// Without the windows the ticks are left as they are and the error returned, so
+// they aren't stored unmarked.
+func ApplyMaintenance(ctx context.Context, storage store.Storage, ticks []store.WebsiteTick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	since := ticks[0].Time
+	for _, tick := range ticks {
+		if tick.Time.Before(since) {
+			since = tick.Time
+		}
+	}
+
+	windows, err := storage.Maintenance.GetWebsiteWindows(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	for i, tick := range ticks {
+		if tick.WebsiteID == nil || tick.Status == store.Maintenance.String() {
+			continue
+		}
+
+		if store.InMaintenance(windows[*tick.WebsiteID], tick.Time) {
+			ticks[i].Status = store.Maintenance.String()
+		}
+	}
+
+	return nil
+}
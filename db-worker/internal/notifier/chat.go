@@ -0,0 +1,41 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// ChatNotifier posts a plain text message to a Slack or Discord incoming webhook.
+type ChatNotifier struct {
+	config      store.ChatConfig
+	channelType string
+}
+
+func (s *ChatNotifier) Notify(ctx context.Context, n Notification) error {
+	text := fmt.Sprintf("*%s*\n%s", n.Subject(), n.Message)
+
+	// Slack reads "text" while Discord reads "content"
+	key := "text"
+	if s.channelType == store.ChannelDiscord {
+		key = "content"
+	}
+
+	body, err := json.Marshal(map[string]string{key: text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return send(req)
+}
@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/mail"
+)
+
+// EmailNotifier sends a plain text mail through the SMTP server in the environment.
+type EmailNotifier struct {
+	config store.EmailConfig
+}
+
+func NewEmailNotifier(cfg store.EmailConfig) *EmailNotifier {
+	return &EmailNotifier{cfg}
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	return mail.Send(ctx, e.config.To, n.Subject(), n.Message)
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// Notification is the message every channel sends when a website changes state.
+type Notification struct {
+	Event      string          `json:"event"`
+	WebsiteID  string          `json:"websiteId"`
+	WebsiteUrl string          `json:"websiteUrl"`
+	Incident   *store.Incident `json:"incident,omitempty"`
+	Message    string          `json:"message"`
+	Time       time.Time       `json:"time"`
+}
+
+func (n Notification) Subject() string {
+	return fmt.Sprintf("[Echo] %s: %s", n.Event, n.WebsiteUrl)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// New builds the notifier for a stored channel.
+func New(ch store.NotificationChannel) (Notifier, error) {
+	switch ch.Type {
+	case store.ChannelWebhook:
+		var cfg store.WebhookConfig
+		if err := json.Unmarshal(ch.Config, &cfg); err != nil {
+			return nil, err
+		}
+
+		return &WebhookNotifier{cfg}, nil
+	case store.ChannelEmail:
+		var cfg store.EmailConfig
+		if err := json.Unmarshal(ch.Config, &cfg); err != nil {
+			return nil, err
+		}
+
+		return NewEmailNotifier(cfg), nil
+	case store.ChannelSlack, store.ChannelDiscord:
+		var cfg store.ChatConfig
+		if err := json.Unmarshal(ch.Config, &cfg); err != nil {
+			return nil, err
+		}
+
+		return &ChatNotifier{cfg, ch.Type}, nil
+	}
+
+	return nil, ErrUnknownChannel
+}
+
+var (
+	ErrUnknownChannel = errors.New("unknown notification channel type")
+)
@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+var client = &http.Client{
+	Timeout:   time.Second * 10,
+	Transport: publicTransport(),
+}
+
+// publicTransport only connects to public addresses. The address is checked after
+// resolution, so a host can't be rebound to an internal address after the channel
+// was created, and redirects are checked like the first request.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: time.Second * 10,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !store.PublicAddress(addr.Addr()) {
+				return fmt.Errorf("%w: %s", store.ErrPrivateDestination, addr.Addr())
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed in place of the destination
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+// WebhookNotifier POSTs the notification as JSON. When a secret is set the body is
+// signed with HMAC-SHA256 in the X-Echo-Signature header.
+type WebhookNotifier struct {
+	config store.WebhookConfig
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if w.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.config.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Echo-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return send(req)
+}
+
+func send(req *http.Request) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, body)
+	}
+
+	return nil
+}
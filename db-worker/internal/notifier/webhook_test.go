@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+func TestWebhookRefusesPrivateAddresses(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	webhook := &WebhookNotifier{store.WebhookConfig{Url: server.URL}}
+
+	err := webhook.Notify(context.Background(), Notification{Event: "incident.opened"})
+	if !errors.Is(err, store.ErrPrivateDestination) {
+		t.Fatalf("error = %v, want %v", err, store.ErrPrivateDestination)
+	}
+	if called {
+		t.Error("the loopback server was called")
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/db-worker/internal/notifier"
+)
+
+var (
+	MaxDeliveryAttempts = 3
+	DeliveryBackoff     = time.Second * 2
+	DeliveryTimeout     = time.Second * 15
+)
+
+// Dispatcher sends incident events to the channels attached to a website and keeps
+// a delivery log of every attempt.
+type Dispatcher struct {
+	storage store.Storage
+}
+
+func NewDispatcher(storage store.Storage) *Dispatcher {
+	return &Dispatcher{storage}
+}
+
+// Dispatch sends the events in the background so it never blocks tick ingestion.
+func (d *Dispatcher) Dispatch(ctx context.Context, events []IncidentEvent) {
+	for _, e := range events {
+		incident := e.Incident
+
+		go d.notify(ctx, e.Type, incident.WebsiteID, &incident, incidentMessage(e))
+	}
+}
+
+// Send notifies the channels of a website about an event that is not an incident.
+func (d *Dispatcher) Send(ctx context.Context, event string, websiteID string, message string) {
+	go d.notify(ctx, event, websiteID, nil, message)
+}
+
+func (d *Dispatcher) notify(ctx context.Context, event string, websiteID string, incident *store.Incident, message string) {
+	channels, err := d.storage.Notification.GetWebsiteChannels(ctx, websiteID)
+	if err != nil {
+		log.Printf("error getting notification channels for website %s:\n%v", websiteID, err)
+		return
+	}
+
+	if len(channels) == 0 {
+		return
+	}
+
+	url, err := d.storage.Website.GetWebsiteUrl(ctx, websiteID)
+	if err != nil {
+		log.Printf("error getting website %s:\n%v", websiteID, err)
+		return
+	}
+
+	n := notifier.Notification{
+		Event:      event,
+		WebsiteID:  websiteID,
+		WebsiteUrl: url,
+		Incident:   incident,
+		Message:    message,
+		Time:       time.Now(),
+	}
+
+	for _, ch := range channels {
+		go d.deliver(ctx, ch, n)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, ch store.NotificationChannel, n notifier.Notification) {
+	delivery := store.NotificationDelivery{
+		ChannelID: ch.ID,
+		WebsiteID: n.WebsiteID,
+		Event:     n.Event,
+	}
+	if n.Incident != nil {
+		delivery.IncidentID = &n.Incident.ID
+	}
+
+	id, err := d.storage.Notification.CreateDelivery(ctx, delivery)
+	if err != nil {
+		log.Printf("error creating delivery log:\n%v", err)
+		return
+	}
+
+	sender, err := notifier.New(ch)
+	if err != nil {
+		d.record(ctx, *id, store.DeliveryFailed, 0, err)
+		return
+	}
+
+	backoff := DeliveryBackoff
+
+	for attempt := 1; attempt <= MaxDeliveryAttempts; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, DeliveryTimeout)
+		err = sender.Notify(sendCtx, n)
+		cancel()
+
+		if err == nil {
+			d.record(ctx, *id, store.DeliveryDelivered, attempt, nil)
+			return
+		}
+
+		log.Printf("attempt %d to notify channel %s failed:\n%v", attempt, ch.ID, err)
+
+		if attempt < MaxDeliveryAttempts {
+			d.record(ctx, *id, store.DeliveryPending, attempt, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+	}
+
+	d.record(ctx, *id, store.DeliveryFailed, MaxDeliveryAttempts, err)
+}
+
+func (d *Dispatcher) record(ctx context.Context, id string, status string, attempts int, deliveryErr error) {
+	if err := d.storage.Notification.UpdateDelivery(ctx, id, status, attempts, deliveryErr); err != nil {
+		log.Printf("error updating delivery log:\n%v", err)
+	}
+}
+
+func incidentMessage(e IncidentEvent) string {
+	regions := strings.Join(e.Incident.AffectedRegions, ", ")
+
+	switch e.Type {
+	case IncidentOpened:
+		return fmt.Sprintf("Website is down in %s since %s.", regions, e.Incident.StartedAt.Format(time.RFC1123))
+	case IncidentResolved:
+		return fmt.Sprintf("Website is back up after %s (affected regions: %s).", time.Duration(e.Incident.DurationSeconds)*time.Second, regions)
+	}
+
+	return e.Type
+}
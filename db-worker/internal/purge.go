@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// exportLine is a line of an archive. The website comes first, then its ticks
+// oldest first and the daily history outliving the raw ticks.
+type exportLine struct {
+	Website *store.DeletedWebsite `json:"website,omitempty"`
+	Tick    *store.WebsiteTick    `json:"tick,omitempty"`
+	Daily   *store.DailyHistory   `json:"daily,omitempty"`
+}
+
+// RunPurger purges deleted websites every interval until ctx is done. It runs
+// apart from the ingest loop, which keeps draining ticks while a large website
+// is exported and purged.
+func RunPurger(ctx context.Context, storage store.Storage, exportDir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			PurgeDeletedWebsites(ctx, storage, exportDir)
+		}
+	}
+}
+
+// PurgeDeletedWebsites purges the websites whose restore window is over, a chunk of
+// ticks at a time. Websites deleted with export are archived to exportDir first.
+// A failed purge is picked up again on the next run.
+func PurgeDeletedWebsites(ctx context.Context, storage store.Storage, exportDir string) {
+	websites, err := storage.Website.GetPurgeableWebsites(ctx)
+	if err != nil {
+		log.Printf("error getting deleted websites:\n%v", err)
+		return
+	}
+
+	for _, w := range websites {
+		if w.ExportOnPurge && w.ExportedAt == nil {
+			path, err := exportWebsite(ctx, storage, w, exportDir)
+			if err != nil {
+				log.Printf("error exporting website %s:\n%v", w.ID, err)
+				continue
+			}
+
+			if err := storage.Website.SetExported(ctx, w.ID); err != nil {
+				log.Printf("error marking website %s as exported:\n%v", w.ID, err)
+				continue
+			}
+
+			log.Printf("Exported website %s to %s", w.ID, path)
+		}
+
+		purged, err := purgeTicks(ctx, storage, w.ID)
+		if err != nil {
+			log.Printf("error purging ticks of website %s, %d purged so far:\n%v", w.ID, purged, err)
+			continue
+		}
+
+		if err := storage.Website.PurgeWebsite(ctx, w.ID); err != nil {
+			log.Printf("error purging website %s:\n%v", w.ID, err)
+			continue
+		}
+
+		log.Printf("Purged website %s with %d ticks", w.ID, purged)
+	}
+}
+
+// purgeTicks deletes the ticks of a website chunk by chunk and returns how many
+// were deleted.
+func purgeTicks(ctx context.Context, storage store.Storage, websiteID string) (int64, error) {
+	var purged int64
+
+	for {
+		deleted, err := storage.WebsiteTick.PurgeTicks(ctx, websiteID)
+		if err != nil {
+			return purged, err
+		}
+
+		if deleted == 0 {
+			return purged, nil
+		}
+
+		purged += deleted
+	}
+}
+
+// exportWebsite writes the history of a website to a gzipped JSON lines file in dir
+// and returns its path. The file only appears once complete.
+func exportWebsite(ctx context.Context, storage store.Storage, w store.DeletedWebsite, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl.gz", w.ID, w.DeletedAt.Format("20060102")))
+
+	file, err := os.CreateTemp(dir, ".export-*")
+	if err != nil {
+		return "", err
+	}
+	//nolint:errcheck
+	defer os.Remove(file.Name())
+	//nolint:errcheck
+	defer file.Close()
+
+	archive := gzip.NewWriter(file)
+	encoder := json.NewEncoder(archive)
+
+	if err := encoder.Encode(exportLine{Website: &w}); err != nil {
+		return "", err
+	}
+
+	err = storage.WebsiteTick.ExportTicks(ctx, w.ID, func(t store.WebsiteTick) error {
+		return encoder.Encode(exportLine{Tick: &t})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	history, err := storage.WebsiteTick.GetDailyHistory(ctx, w.ID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, d := range history {
+		if err := encoder.Encode(exportLine{Daily: &d}); err != nil {
+			return "", err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return path, os.Rename(file.Name(), path)
+}
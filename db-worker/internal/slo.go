@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+const (
+	SLOFastBurn  = "slo.fast_burn"
+	SLOSlowBurn  = "slo.slow_burn"
+	SLOExhausted = "slo.exhausted"
+	SLORecovered = "slo.recovered"
+)
+
+var sloEvents = map[store.SLOState]string{
+	store.SLOFastBurn:  SLOFastBurn,
+	store.SLOSlowBurn:  SLOSlowBurn,
+	store.SLOExhausted: SLOExhausted,
+	store.SLOOk:        SLORecovered,
+}
+
+// RunSLOEvaluator evaluates the SLOs every interval until ctx is done, apart from
+// the ingest loop.
+func RunSLOEvaluator(ctx context.Context, storage store.Storage, dispatcher *Dispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			EvaluateSLOs(ctx, storage, dispatcher)
+		}
+	}
+}
+
+// EvaluateSLOs stores the burn state of every SLO and notifies the channels of its
+// website when the state changes.
+func EvaluateSLOs(ctx context.Context, storage store.Storage, dispatcher *Dispatcher) {
+	slos, err := storage.SLO.GetAllSLOs(ctx)
+	if err != nil {
+		log.Printf("error getting slos:\n%v", err)
+		return
+	}
+
+	now := time.Now()
+
+	for _, slo := range slos {
+		status, err := storage.SLO.GetStatus(ctx, slo, now)
+		if err != nil {
+			log.Printf("error evaluating slo %s:\n%v", slo.ID, err)
+			continue
+		}
+
+		changed, err := storage.SLO.SetState(ctx, slo.ID, status.CurrentState)
+		if err != nil {
+			log.Printf("error storing state of slo %s:\n%v", slo.ID, err)
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		log.Printf("SLO %s of website %s is now %s", slo.ID, slo.WebsiteID, status.CurrentState)
+		dispatcher.Send(ctx, sloEvents[status.CurrentState], slo.WebsiteID, sloMessage(*status))
+	}
+}
+
+func sloMessage(s store.SLOStatus) string {
+	switch s.CurrentState {
+	case store.SLOFastBurn, store.SLOSlowBurn:
+		var rate float64
+		for _, b := range s.BurnRates {
+			rate = max(rate, b.Rate)
+		}
+		return fmt.Sprintf("SLO %s is burning its error budget %.1fx faster than sustainable, %.1f%% of the budget is left.", s.Name, rate, s.BudgetRemaining)
+	case store.SLOExhausted:
+		return fmt.Sprintf("SLO %s has spent its error budget, availability is %.3f%% against a target of %.3f%%.", s.Name, s.SLI, s.Target)
+	}
+
+	return fmt.Sprintf("SLO %s recovered, %.1f%% of the error budget is left.", s.Name, s.BudgetRemaining)
+}
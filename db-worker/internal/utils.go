@@ -4,36 +4,192 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"time"
 
 	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
 	"github.com/redis/go-redis/v9"
 )
 
-func AddToBatch(res []redis.XStream, ticks *[]store.WebsiteTick) {
+var (
+	ConsumerGroup = "db-worker"
+	MaxDeliveries = int64(5)
+	ReclaimIdle   = time.Second * 30
+)
+
+// Batch holds the ticks waiting to be inserted with their messages, which are
+// acknowledged once the ticks are committed.
+type Batch struct {
+	Ticks    []store.WebsiteTick
+	Messages []redis.XMessage
+}
+
+func (b *Batch) Len() int {
+	return len(b.Ticks)
+}
+
+func (b *Batch) reset() {
+	b.Ticks = nil
+	b.Messages = nil
+}
+
+func AddToBatch(ctx context.Context, rclient redisClient.RedisClient, res []redis.XStream, batch *Batch) {
 	for _, i := range res {
 		for _, j := range i.Messages {
-			data := j.Values["data"].(string)
+			addMessage(ctx, rclient, j, batch)
+		}
+	}
+}
 
-			var tick store.WebsiteTick
+func addMessage(ctx context.Context, rclient redisClient.RedisClient, msg redis.XMessage, batch *Batch) {
+	data, _ := msg.Values["data"].(string)
 
-			err := json.Unmarshal([]byte(data), &tick)
-			if err != nil {
-				log.Printf("error parsing redis data:\n%v", err)
-				continue
-			}
+	var tick store.WebsiteTick
 
-			*ticks = append(*ticks, tick)
+	err := json.Unmarshal([]byte(data), &tick)
+	if err != nil {
+		// A message that can't be parsed will never succeed, drop it right away
+		log.Printf("error parsing redis data:\n%v", err)
+		deadLetter(ctx, rclient, msg, "parse: "+err.Error())
+		return
+	}
+
+	batch.Ticks = append(batch.Ticks, tick)
+	batch.Messages = append(batch.Messages, msg)
+}
+
+// Reclaim adds the messages left pending by failed batches or crashed consumers to
+// the batch, dead lettering the ones delivered too many times.
+func Reclaim(ctx context.Context, rclient redisClient.RedisClient, consumer string, batch *Batch) {
+	msgs := rclient.XAutoClaim(ctx, redisClient.DatabaseStream, ConsumerGroup, consumer, ReclaimIdle)
+	if len(msgs) == 0 {
+		return
+	}
+
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+
+	counts := rclient.DeliveryCounts(ctx, redisClient.DatabaseStream, ConsumerGroup, consumer, ids...)
+
+	log.Printf("Reclaimed %d pending messages.", len(msgs))
+
+	for _, msg := range msgs {
+		if counts[msg.ID] > MaxDeliveries {
+			deadLetter(ctx, rclient, msg, "insert: exceeded max deliveries")
+			continue
 		}
+
+		addMessage(ctx, rclient, msg, batch)
 	}
 }
 
-func ProcessBatch(ctx context.Context, storage store.Storage, ticks *[]store.WebsiteTick) {
-	err := storage.WebsiteTick.BatchInsertTicks(ctx, *ticks)
+func deadLetter(ctx context.Context, rclient redisClient.RedisClient, msg redis.XMessage, reason string) {
+	err := rclient.DeadLetter(ctx, redisClient.DatabaseDeadLetterStream, redisClient.DatabaseStream, msg, reason)
+	if err != nil {
+		// Leave it pending so it is retried
+		return
+	}
+
+	rclient.XAck(ctx, redisClient.DatabaseStream, ConsumerGroup, msg.ID)
+	log.Printf("Moved message %s to dead letter stream: %s", msg.ID, reason)
+}
+
+// ProcessBatch inserts the ticks and acknowledges their messages. Ticks the database
+// rejects are dead lettered on their own, the rest of a batch failing otherwise is
+// left pending and reclaimed later, as is a batch whose maintenance windows can't
+// be read.
+func ProcessBatch(ctx context.Context, storage store.Storage, rclient redisClient.RedisClient, tracker *IncidentTracker, dispatcher *Dispatcher, batch *Batch) {
+	if err := ApplyMaintenance(ctx, storage, batch.Ticks); err != nil {
+		log.Printf("error getting maintenance windows, %d messages left pending:\n%v", batch.Len(), err)
+		batch.reset()
+		return
+	}
+
+	res, err := InsertIsolating(ctx, storage.WebsiteTick.BatchInsertTicks, batch.Ticks)
+
+	for i, reason := range res.Rejected {
+		deadLetter(ctx, rclient, batch.Messages[i], "insert: "+reason.Error())
+	}
+
+	if len(res.Stored) > 0 {
+		ids := make([]string, len(res.Stored))
+		for j, i := range res.Stored {
+			ids[j] = batch.Messages[i].ID
+		}
+
+		rclient.XAck(ctx, redisClient.DatabaseStream, ConsumerGroup, ids...)
+	}
+
 	if err != nil {
-		log.Printf("error inserting ticks to db:\n%v", err)
+		pending := batch.Len() - len(res.Stored) - len(res.Rejected)
+		log.Printf("error inserting ticks to db, %d messages left pending:\n%v", pending, err)
 	}
 
-	log.Printf("Inserted %d messages to database.", len(*ticks))
+	log.Printf("Inserted %d of %d messages to database.", len(res.Inserted), batch.Len())
+
+	// Only new ticks count towards incidents, redelivered ones were seen already
+	events := tracker.Process(ctx, res.Inserted)
+	dispatcher.Dispatch(ctx, events)
+
+	TrackCertificates(ctx, storage, dispatcher, res.Inserted)
+	TrackDNS(ctx, storage, dispatcher, res.Inserted)
+	TrackContent(ctx, storage, dispatcher, res.Inserted)
+
+	batch.reset()
+}
+
+// InsertResult tells which ticks of a batch were stored and which were rejected, by
+// their index in the batch.
+type InsertResult struct {
+	// Ticks that weren't stored before
+	Inserted []store.WebsiteTick
+	// Committed ticks, including ones already stored
+	Stored []int
+	// Ticks the database rejected, with the reason
+	Rejected map[int]error
+}
+
+// InsertIsolating inserts the ticks with insert, splitting the batch in halves
+// when the database rejects some of its ticks until only those are left out. It
+// stops at the first error that isn't a rejection and returns it with the ticks
+// handled so far.
+func InsertIsolating(ctx context.Context, insert func(context.Context, []store.WebsiteTick) ([]store.WebsiteTick, error), ticks []store.WebsiteTick) (InsertResult, error) {
+	res := InsertResult{Rejected: map[int]error{}}
+
+	err := insertRange(ctx, insert, ticks, 0, &res)
+	return res, err
+}
+
+func insertRange(ctx context.Context, insert func(context.Context, []store.WebsiteTick) ([]store.WebsiteTick, error), ticks []store.WebsiteTick, offset int, res *InsertResult) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	inserted, err := insert(ctx, ticks)
+	if err == nil {
+		res.Inserted = append(res.Inserted, inserted...)
+		for i := range ticks {
+			res.Stored = append(res.Stored, offset+i)
+		}
+		return nil
+	}
+
+	if !store.IsRejected(err) {
+		return err
+	}
+
+	if len(ticks) == 1 {
+		res.Rejected[offset] = err
+		return nil
+	}
+
+	mid := len(ticks) / 2
+
+	if err := insertRange(ctx, insert, ticks[:mid], offset, res); err != nil {
+		return err
+	}
 
-	*ticks = nil
+	return insertRange(ctx, insert, ticks[mid:], offset+mid, res)
 }
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeInsert fails a whole batch like COPY does when any of its ticks has the
+// status bad, and with err when set.
+func fakeInsert(calls *int, err error) func(context.Context, []store.WebsiteTick) ([]store.WebsiteTick, error) {
+	return func(ctx context.Context, ticks []store.WebsiteTick) ([]store.WebsiteTick, error) {
+		*calls++
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range ticks {
+			if t.Status == "bad" {
+				return nil, &pgconn.PgError{Code: "22P02", Message: "invalid input value for enum website_status"}
+			}
+		}
+
+		return ticks, nil
+	}
+}
+
+func ticksWithStatus(statuses ...string) []store.WebsiteTick {
+	ticks := make([]store.WebsiteTick, len(statuses))
+	for i, status := range statuses {
+		ticks[i] = store.WebsiteTick{Time: time.Unix(int64(i), 0), Status: status}
+	}
+
+	return ticks
+}
+
+func TestInsertIsolatingRejectsOnlyBadTicks(t *testing.T) {
+	var calls int
+	ticks := ticksWithStatus("up", "up", "bad", "down", "up", "up", "bad", "up")
+
+	res, err := InsertIsolating(context.Background(), fakeInsert(&calls, nil), ticks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []int{0, 1, 3, 4, 5, 7}; !slices.Equal(res.Stored, want) {
+		t.Errorf("stored %v, want %v", res.Stored, want)
+	}
+
+	if len(res.Rejected) != 2 || res.Rejected[2] == nil || res.Rejected[6] == nil {
+		t.Errorf("rejected %v, want ticks 2 and 6", res.Rejected)
+	}
+
+	if len(res.Inserted) != 6 {
+		t.Errorf("inserted %d ticks, want 6", len(res.Inserted))
+	}
+}
+
+func TestInsertIsolatingInsertsCleanBatchOnce(t *testing.T) {
+	var calls int
+
+	res, err := InsertIsolating(context.Background(), fakeInsert(&calls, nil), ticksWithStatus("up", "down", "up"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("insert called %d times, want 1", calls)
+	}
+	if len(res.Stored) != 3 || len(res.Rejected) != 0 {
+		t.Errorf("stored %v and rejected %v, want all stored", res.Stored, res.Rejected)
+	}
+}
+
+func TestInsertIsolatingKeepsBatchPendingOnOtherErrors(t *testing.T) {
+	var calls int
+	unavailable := errors.New("connection refused")
+
+	res, err := InsertIsolating(context.Background(), fakeInsert(&calls, unavailable), ticksWithStatus("up", "bad", "up"))
+	if !errors.Is(err, unavailable) {
+		t.Fatalf("error %v, want %v", err, unavailable)
+	}
+
+	// Nothing is dead lettered while the database is unavailable
+	if calls != 1 || len(res.Stored) != 0 || len(res.Rejected) != 0 {
+		t.Errorf("%d calls, stored %v and rejected %v, want the batch left as it is", calls, res.Stored, res.Rejected)
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/DevanshBhavsar3/echo/common/config"
 	"github.com/DevanshBhavsar3/echo/common/db"
 	"github.com/DevanshBhavsar3/echo/common/db/store"
 	"github.com/DevanshBhavsar3/echo/common/redisClient"
@@ -11,7 +15,7 @@ import (
 )
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	rclient := redisClient.NewRedisClient(ctx)
@@ -21,15 +25,20 @@ func main() {
 
 	storage := store.NewStorage(database)
 
-	forever := make(chan bool)
+	syncInterval := config.GetDuration("PUBLISHER_SYNC_INTERVAL", time.Second*10)
+	leaseTTL := config.GetDuration("PUBLISHER_LEASE_TTL", time.Second*15)
 
-	go internal.StartInterval(ctx, storage, rclient, "30s", time.Second*30)
+	internal.LegacyStream = config.Get("PUBLISHER_LEGACY_STREAM") == "true"
 
-	go internal.StartInterval(ctx, storage, rclient, "1m", time.Minute)
+	publisherId := config.Get("PUBLISHER_ID")
+	if publisherId == "" {
+		publisherId, _ = os.Hostname()
+	}
 
-	go internal.StartInterval(ctx, storage, rclient, "3m", time.Minute*3)
+	leader := internal.NewLeader(rclient, publisherId, leaseTTL)
 
-	go internal.StartInterval(ctx, storage, rclient, "5m", time.Minute*5)
-
-	<-forever
+	leader.Run(ctx, func(ctx context.Context) {
+		scheduler := internal.NewScheduler(storage, leader)
+		scheduler.Run(ctx, syncInterval)
+	})
 }
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
+)
+
+// CheckHeartbeat records a missed heartbeat in every region of a monitor when no
+// ping arrived within its frequency plus grace period. Monitors that never got a
+// ping count from their creation, and resumed monitors from their resume. It stops
+// with ErrNotLeader once the lease is lost.
+func CheckHeartbeat(ctx context.Context, storage store.Storage, client StreamWriter, website store.ScheduledWebsite, scheduledAt time.Time) error {
+	h, err := storage.Heartbeat.GetHeartbeat(ctx, website.ID)
+	if err != nil {
+		log.Printf("failed to get heartbeat of website %s:\n%v", website.ID, err)
+		return nil
+	}
+
+	var check store.CheckSpec
+	if len(website.Payloads) > 0 && len(website.Payloads[0].Check) > 0 {
+		if err := json.Unmarshal(website.Payloads[0].Check, &check); err != nil {
+			log.Printf("failed to parse check of website %s:\n%v", website.ID, err)
+			return nil
+		}
+	}
+
+	if scheduledAt.Sub(h.ExpectedSince()) <= website.Frequency+check.Grace() {
+		return nil
+	}
+
+	// Ticks are stored at their scheduled time so a missed heartbeat is recorded once
+	ticks := h.Ticks(store.Down, scheduledAt, 0, store.HeartbeatResult{Event: store.HeartbeatMissed})
+
+	for _, tick := range ticks {
+		data, err := json.Marshal(tick)
+		if err != nil {
+			log.Printf("failed to marshal tick:\n%v", err)
+			continue
+		}
+
+		err = client.XAdd(ctx, redisClient.DatabaseStream, data)
+		if errors.Is(err, ErrNotLeader) {
+			return err
+		}
+		if err != nil {
+			log.Printf("failed to add tick to stream:\n%v", err)
+			continue
+		}
+	}
+
+	return nil
+}
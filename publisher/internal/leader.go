@@ -0,0 +1,264 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	LeaderKey  = "echo:publisher:leader"
+	FencingKey = "echo:publisher:fencing"
+)
+
+// acquireScript takes the lease when it is free and only then issues the next
+// fencing token, stored in the lease value as id:token.
+var acquireScript = redis.NewScript(`
+	if redis.call("EXISTS", KEYS[1]) == 1 then
+		return 0
+	end
+	local token = redis.call("INCR", KEYS[2])
+	redis.call("SET", KEYS[1], ARGV[1] .. ":" .. token, "PX", ARGV[2])
+	return token
+`)
+
+// fencedXAddScript adds to a stream only while the lease still holds the value,
+// and so the token, of the caller.
+var fencedXAddScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+		return 0
+	end
+	redis.call("XADD", KEYS[2], "*", "data", ARGV[2])
+	return 1
+`)
+
+var renewScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	end
+	return 0
+`)
+
+var releaseScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
+// Leader holds a lease in Redis so only one publisher schedules at a time. Every
+// acquisition gets a new fencing token, which is stored in the lease value. Writes
+// through XAdd are fenced, so a leader whose lease expired can't publish once
+// another one took over.
+type Leader struct {
+	client redisClient.RedisClient
+	id     string
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	value    string
+	token    int64
+	deadline time.Time
+	holder   string
+}
+
+func NewLeader(client redisClient.RedisClient, id string, ttl time.Duration) *Leader {
+	return &Leader{
+		client: client,
+		id:     id,
+		ttl:    ttl,
+	}
+}
+
+// IsLeader reports whether the lease is held and has not expired locally.
+func (l *Leader) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.value != "" && time.Now().Before(l.deadline)
+}
+
+func (l *Leader) Token() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.token
+}
+
+// Run campaigns for the lease and runs lead while it is held. lead is cancelled as
+// soon as the lease is lost, and the lease is released when ctx is done.
+func (l *Leader) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	var stop func()
+
+	for {
+		if stop != nil {
+			if !l.renew(ctx) {
+				log.Printf("Publisher %s lost leadership (token %d)", l.id, l.Token())
+				stop()
+				stop = nil
+			}
+		} else if l.acquire(ctx) {
+			log.Printf("Publisher %s acquired leadership (token %d)", l.id, l.Token())
+			stop = start(ctx, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			if stop != nil {
+				stop()
+			}
+			l.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// start runs lead in the background and returns a func cancelling it and waiting for it to return.
+func start(ctx context.Context, lead func(ctx context.Context)) func() {
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (l *Leader) acquire(ctx context.Context) bool {
+	start := time.Now()
+
+	token, err := acquireScript.Run(ctx, l.client.Client, []string{LeaderKey, FencingKey}, l.id, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		log.Printf("failed to acquire leadership:\n%v", err)
+		return false
+	}
+
+	if token == 0 {
+		l.logHolder(ctx)
+		return false
+	}
+
+	value := fmt.Sprintf("%s:%d", l.id, token)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.value = value
+	l.token = token
+	l.deadline = start.Add(l.ttl)
+	l.holder = value
+
+	return true
+}
+
+// XAdd adds data to a stream while the lease is held with the current fencing
+// token, and returns ErrNotLeader otherwise.
+func (l *Leader) XAdd(ctx context.Context, stream string, data any) error {
+	l.mu.Lock()
+	value := l.value
+	l.mu.Unlock()
+
+	if value == "" {
+		return ErrNotLeader
+	}
+
+	added, err := fencedXAddScript.Run(ctx, l.client.Client, []string{LeaderKey, stream}, value, data).Int()
+	if err != nil {
+		log.Printf("failed to add data to redis stream:\n%v", err)
+		return err
+	}
+
+	if added == 0 {
+		return ErrNotLeader
+	}
+
+	return nil
+}
+
+func (l *Leader) renew(ctx context.Context) bool {
+	l.mu.Lock()
+	value := l.value
+	l.mu.Unlock()
+
+	start := time.Now()
+
+	res, err := renewScript.Run(ctx, l.client.Client, []string{LeaderKey}, value, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		log.Printf("failed to renew leadership:\n%v", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil || res == 0 {
+		// Keep leading on transient errors until the lease would have expired
+		if err != nil && time.Now().Before(l.deadline) {
+			return true
+		}
+
+		l.value = ""
+		return false
+	}
+
+	l.deadline = start.Add(l.ttl)
+	return true
+}
+
+// release gives up the lease so another instance can take over without waiting for the TTL.
+func (l *Leader) release() {
+	l.mu.Lock()
+	value := l.value
+	l.value = ""
+	l.mu.Unlock()
+
+	if value == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	err := releaseScript.Run(ctx, l.client.Client, []string{LeaderKey}, value).Err()
+	if err != nil {
+		log.Printf("failed to release leadership:\n%v", err)
+		return
+	}
+
+	log.Printf("Publisher %s released leadership", l.id)
+}
+
+// logHolder logs the current lease holder whenever it changes.
+func (l *Leader) logHolder(ctx context.Context) {
+	holder, err := l.client.Client.Get(ctx, LeaderKey).Result()
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if holder != l.holder {
+		log.Printf("Publisher %s is standing by, leader is %s", l.id, holder)
+		l.holder = holder
+	}
+}
+
+var (
+	ErrNotLeader = errors.New("lease is held by another publisher")
+)
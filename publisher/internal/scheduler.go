@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+type entry struct {
+	website store.ScheduledWebsite
+	next    time.Time
+	index   int
+}
+
+// queue is a min-heap of websites ordered by their next due time.
+type queue []*entry
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
+
+type Scheduler struct {
+	storage store.Storage
+	leader  *Leader
+	entries map[string]*entry
+	queue   queue
+	// Maintenance windows skipping checks, by website
+	skipped map[string][]store.MaintenanceWindow
+}
+
+func NewScheduler(storage store.Storage, leader *Leader) *Scheduler {
+	return &Scheduler{
+		storage: storage,
+		leader:  leader,
+		entries: map[string]*entry{},
+		queue:   queue{},
+		skipped: map[string][]store.MaintenanceWindow{},
+	}
+}
+
+// Sync reloads the websites from the database. New websites are scheduled, removed
+// ones dropped and websites with a new frequency rescheduled. Paused websites are
+// dropped too, after resuming the ones due. The maintenance windows skipping checks
+// are reloaded with them.
+func (s *Scheduler) Sync(ctx context.Context) error {
+	resumed, err := s.storage.Website.ResumeDueWebsites(ctx)
+	if err != nil {
+		return err
+	}
+
+	if resumed > 0 {
+		log.Printf("Resumed %d paused websites", resumed)
+	}
+
+	websites, err := s.storage.Website.GetScheduledWebsites(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	windows, err := s.storage.Maintenance.GetWebsiteWindows(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	s.skipped = map[string][]store.MaintenanceWindow{}
+	for websiteID, ws := range windows {
+		for _, w := range ws {
+			if w.SkipChecks {
+				s.skipped[websiteID] = append(s.skipped[websiteID], w)
+			}
+		}
+	}
+	seen := map[string]bool{}
+
+	for _, w := range websites {
+		if w.Frequency <= 0 {
+			continue
+		}
+
+		seen[w.ID] = true
+
+		e, ok := s.entries[w.ID]
+		if !ok {
+			e = &entry{
+				website: w,
+				next:    NextDue(w.ID, w.Frequency, now),
+			}
+			s.entries[w.ID] = e
+			heap.Push(&s.queue, e)
+			continue
+		}
+
+		if e.website.Frequency != w.Frequency {
+			e.next = NextDue(w.ID, w.Frequency, now)
+			heap.Fix(&s.queue, e.index)
+		}
+		e.website = w
+	}
+
+	for id, e := range s.entries {
+		if !seen[id] {
+			heap.Remove(&s.queue, e.index)
+			delete(s.entries, id)
+		}
+	}
+
+	return nil
+}
+
+// Run publishes websites as they become due and resyncs every syncInterval.
+func (s *Scheduler) Run(ctx context.Context, syncInterval time.Duration) {
+	if err := s.Sync(ctx); err != nil {
+		log.Printf("Failed to get websites data:\n%v", err)
+	}
+
+	log.Printf("Scheduling %d websites", len(s.entries))
+
+	sync := time.NewTicker(syncInterval)
+	defer sync.Stop()
+
+	timer := time.NewTimer(s.wait())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sync.C:
+			if err := s.Sync(ctx); err != nil {
+				log.Printf("Failed to get websites data:\n%v", err)
+			}
+		case <-timer.C:
+			s.publishDue(ctx, time.Now())
+		}
+
+		timer.Reset(s.wait())
+	}
+}
+
+func (s *Scheduler) publishDue(ctx context.Context, now time.Time) {
+	// The lease can expire before Run is cancelled
+	if !s.leader.IsLeader() {
+		return
+	}
+
+	published := 0
+
+	for s.queue.Len() > 0 && !s.queue[0].next.After(now) {
+		e := s.queue[0]
+
+		var err error
+
+		switch {
+		case store.InMaintenance(s.skipped[e.website.ID], e.next):
+			// Websites in maintenance aren't checked, the run is skipped
+		case e.website.CheckType == store.CheckHeartbeat:
+			// Heartbeat monitors are pinged by their jobs, only missed pings are published
+			err = CheckHeartbeat(ctx, s.storage, s.leader, e.website, e.next)
+			published++
+		default:
+			err = Publish(ctx, s.leader, e.website, e.next)
+			published++
+		}
+
+		// Writes are fenced by the lease, the new leader publishes the rest
+		if errors.Is(err, ErrNotLeader) {
+			log.Printf("Stopped publishing, the lease of token %d was taken over", s.leader.Token())
+			return
+		}
+
+		// Skip missed runs instead of publishing them in a burst
+		e.next = e.next.Add(e.website.Frequency)
+		if !e.next.After(now) {
+			e.next = NextDue(e.website.ID, e.website.Frequency, now)
+		}
+		heap.Fix(&s.queue, 0)
+	}
+
+	if published > 0 {
+		log.Printf("Published %d websites (token %d)", published, s.leader.Token())
+	}
+}
+
+// wait returns the time until the next website is due.
+func (s *Scheduler) wait() time.Duration {
+	if s.queue.Len() == 0 {
+		return time.Minute
+	}
+
+	return time.Until(s.queue[0].next)
+}
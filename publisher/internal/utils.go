@@ -3,6 +3,8 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"hash/fnv"
 	"log"
 	"time"
 
@@ -10,42 +12,58 @@ import (
 	"github.com/DevanshBhavsar3/echo/common/redisClient"
 )
 
-func StartInterval(ctx context.Context, storage store.Storage, client redisClient.RedisClient, name string, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// LegacyStream publishes every check to the shared website stream, for workers that
+// don't read their region stream yet.
+var LegacyStream = false
 
-	log.Printf("Starting interval %s with duration %v", name, interval)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			AddWebsite(ctx, storage, client, name)
-		}
-	}
+// StreamWriter adds data to a Redis stream. The scheduler writes through the
+// Leader, which fences the writes with its lease.
+type StreamWriter interface {
+	XAdd(ctx context.Context, stream string, data any) error
 }
 
-func AddWebsite(ctx context.Context, storage store.Storage, client redisClient.RedisClient, freq string) {
-	payload, err := storage.Website.GetWebsiteByFrequency(ctx, freq)
-	if err != nil {
-		log.Printf("Failed to get websites data:\n%v", err)
-		return
-	}
-
-	log.Printf("Publishing %d websites for frequency %s", len(payload), freq)
-
-	for _, w := range payload {
-		data, err := json.Marshal(w)
+// Publish adds the check of each region of the website to the stream of that region.
+// scheduledAt identifies the run, so a check delivered twice is stored once. It
+// stops with ErrNotLeader once the lease is lost.
+func Publish(ctx context.Context, client StreamWriter, website store.ScheduledWebsite, scheduledAt time.Time) error {
+	for _, p := range website.Payloads {
+		p.ScheduledAt = scheduledAt
+		data, err := json.Marshal(p)
 		if err != nil {
 			log.Printf("failed to marshal website:\n%v", err)
 			continue
 		}
 
-		err = client.XAdd(ctx, redisClient.WebsiteStream, data)
+		stream := redisClient.RegionStream(p.RegionName)
+		if LegacyStream {
+			stream = redisClient.WebsiteStream
+		}
+
+		err = client.XAdd(ctx, stream, data)
+		if errors.Is(err, ErrNotLeader) {
+			return err
+		}
 		if err != nil {
 			log.Printf("failed to add website to stream:\n%v", err)
 			continue
 		}
 	}
+
+	return nil
+}
+
+// NextDue returns the first due time of a website after t. Each website gets a
+// fixed offset inside its frequency derived from its id, so checks are spread
+// across the interval and keep the same phase across restarts.
+func NextDue(id string, frequency time.Duration, t time.Time) time.Time {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	offset := time.Duration(h.Sum64() % uint64(frequency))
+
+	next := t.Truncate(frequency).Add(offset)
+	if !next.After(t) {
+		next = next.Add(frequency)
+	}
+
+	return next
 }
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextDue(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	frequency := time.Minute
+
+	for _, id := range []string{"website-a", "website-b", "website-c"} {
+		first := NextDue(id, frequency, start)
+		if !first.After(start) || first.After(start.Add(frequency)) {
+			t.Fatalf("NextDue(%s) = %s, want within a frequency after %s", id, first, start)
+		}
+
+		// The offset of a website stays the same across intervals and restarts
+		phase := first.Sub(first.Truncate(frequency))
+		for _, t0 := range []time.Time{start.Add(time.Second), first.Add(-time.Nanosecond), first, start.Add(time.Hour + 17*time.Second)} {
+			next := NextDue(id, frequency, t0)
+			if !next.After(t0) || next.After(t0.Add(frequency)) {
+				t.Errorf("NextDue(%s, %s) = %s, want within a frequency after it", id, t0, next)
+			}
+			if got := next.Sub(next.Truncate(frequency)); got != phase {
+				t.Errorf("NextDue(%s, %s) has offset %s, want %s", id, t0, got, phase)
+			}
+		}
+
+		if next := NextDue(id, frequency, first); !next.Equal(first.Add(frequency)) {
+			t.Errorf("NextDue(%s) at its due time = %s, want %s", id, next, first.Add(frequency))
+		}
+	}
+
+	if NextDue("website-a", time.Hour, start).Equal(NextDue("website-b", time.Hour, start)) {
+		t.Error("websites share the same due time, want them spread over the frequency")
+	}
+}
@@ -29,10 +29,16 @@ import {
 } from '../ui/select'
 
 const frequencies = [
+    { value: '10s', label: '10 Seconds' },
     { value: '30s', label: '30 Seconds' },
     { value: '1m', label: '1 Minute' },
     { value: '3m', label: '3 Minutes' },
     { value: '5m', label: '5 Minutes' },
+    { value: '15m', label: '15 Minutes' },
+    { value: '30m', label: '30 Minutes' },
+    { value: '1h', label: '1 Hour' },
+    { value: '6h', label: '6 Hours' },
+    { value: '24h', label: '24 Hours' },
 ]
 
 interface DialogProps {
@@ -55,15 +55,23 @@ export const columns: ColumnDef<Uptime>[] = [
             return 'Last ' + toTime.diff(fromTime, 'day') + ' days'
         },
     },
-    { accessorKey: 'availability', header: 'Availability' },
-    { accessorKey: 'avg_response_time', header: 'Avg. Response Times' },
+    {
+        accessorKey: 'availability',
+        header: 'Availability',
+        cell: ({ row }) => row.original.availability ?? 'No data',
+    },
+    {
+        accessorKey: 'avg_response_time',
+        header: 'Avg. Response Times',
+        cell: ({ row }) => row.original.avg_response_time ?? 'No data',
+    },
 ]
 
 export type Uptime = {
     custom?: boolean
     time: string
-    availability: string
-    avg_response_time: string
+    availability: string | null
+    avg_response_time: string | null
 }
 
 export function UptimeTable({ monitor }: { monitor: Monitor }) {
@@ -5,23 +5,21 @@ export function cn(...inputs: ClassValue[]) {
     return twMerge(clsx(inputs))
 }
 
-// Convert monitor.frequency (e.g., "30s", "1m", "3m", "5m") to milliseconds
+// Convert monitor.frequency (e.g., "30s", "1m", "1h30m") to milliseconds
 export function frequencyToMs(freq: string): number {
-    const match = freq.match(/^(\d+)([sm])$/)
-    if (!match) {
-        return 30000
+    const units: Record<string, number> = {
+        h: 60 * 60 * 1000,
+        m: 60 * 1000,
+        s: 1000,
     }
 
-    const value = parseInt(match[1], 10)
-
-    const unit = match[2]
-    if (unit === 's') {
-        return value * 1000
-    }
-
-    if (unit === 'm') {
-        return value * 60 * 1000
+    const parts = [...freq.matchAll(/(\d+)([hms])/g)]
+    if (parts.length === 0 || parts.map((p) => p[0]).join('') !== freq) {
+        return 30000
     }
 
-    return 30000
+    return parts.reduce(
+        (total, [, value, unit]) => total + parseInt(value, 10) * units[unit],
+        0
+    )
 }
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"time"
 
@@ -16,6 +15,9 @@ import (
 var (
 	REGION    = config.Get("REGION")
 	WORKER_ID = config.Get("WORKER_ID")
+
+	// Also read the shared stream while publishers are migrated to region streams
+	WORKER_LEGACY_STREAM = config.Get("WORKER_LEGACY_STREAM") == "true"
 )
 
 func main() {
@@ -34,61 +36,34 @@ func main() {
 		log.Fatalf("failed to determine region:\n%v", err)
 	}
 
-	// Create consumer group
-	rclient.XGroupCreate(ctx, redisClient.WebsiteStream, REGION)
-
-	for {
-		// Get messages from streams
-		res := rclient.XReadGroup(ctx, redisClient.WebsiteStream, REGION, WORKER_ID)
-
-		for _, i := range res {
-			var processedMsg []string
+	internal.SnapshotInterval = config.GetDuration("WORKER_DNS_SNAPSHOT_INTERVAL", internal.SnapshotInterval)
 
-			for _, j := range i.Messages {
-				data := j.Values["data"].(string)
-
-				var payload redisClient.RedisPayload
-				err := json.Unmarshal([]byte(data), &payload)
-				if err != nil {
-					log.Printf("error parsing redis message:\n%v", err)
-					continue
-				}
-
-				// Check if the website is of this worker's region
-				if payload.RegionName != REGION {
-					continue
-				}
+	consumer := internal.NewConsumer(rclient, *region, WORKER_ID, WORKER_LEGACY_STREAM)
 
-				// Ping the website
-				status, responseTime := internal.Ping(payload.Url)
-
-				tick := store.WebsiteTick{
-					Time:           time.Now(),
-					ResponseTimeMS: &responseTime,
-					Status:         status.String(),
-					RegionID:       region.ID,
-					WebsiteID:      &payload.ID,
-				}
+	// Create consumer groups
+	for _, stream := range consumer.Streams {
+		rclient.XGroupCreate(ctx, stream, REGION)
+	}
 
-				encodedTick, err := json.Marshal(tick)
-				if err != nil {
-					log.Printf("error marshaling tick:\n%v", err)
-					continue
-				}
+	// Pick up what crashed workers left unacknowledged
+	consumer.Reclaim(ctx)
 
-				// Add the tick to database stream
-				err = rclient.XAdd(ctx, redisClient.DatabaseStream, encodedTick)
+	reclaimTicker := time.NewTicker(internal.ReclaimIdle)
+	defer reclaimTicker.Stop()
 
-				if err == nil {
-					log.Printf("Processed message for region %s", REGION)
-					processedMsg = append(processedMsg, j.ID)
+	for {
+		select {
+		case <-reclaimTicker.C:
+			consumer.Reclaim(ctx)
+		default:
+			// Get messages from streams
+			res := rclient.XReadGroupStreams(ctx, consumer.Streams, REGION, WORKER_ID)
+
+			for _, i := range res {
+				for _, j := range i.Messages {
+					consumer.Handle(ctx, i.Stream, j)
 				}
 			}
-
-			// Acknowlege processed messages
-			if len(processedMsg) > 0 {
-				rclient.XAck(ctx, redisClient.WebsiteStream, REGION, processedMsg...)
-			}
 		}
 	}
 }
@@ -6,6 +6,9 @@ require (
 	github.com/DevanshBhavsar3/echo/common/config v0.0.0-00010101000000-000000000000
 	github.com/DevanshBhavsar3/echo/common/db v0.0.0-00010101000000-000000000000
 	github.com/DevanshBhavsar3/echo/common/redisClient v0.0.0-00010101000000-000000000000
+	github.com/andybalholm/cascadia v1.3.3
+	github.com/redis/go-redis/v9 v9.11.0
+	golang.org/x/net v0.33.0
 )
 
 require (
@@ -16,7 +19,7 @@ require (
 	github.com/jackc/pgx/v5 v5.7.5 // indirect
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/redis/go-redis/v9 v9.11.0 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
 	golang.org/x/crypto v0.39.0 // indirect
 	golang.org/x/sync v0.15.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
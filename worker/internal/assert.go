@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// Assert reports whether the response body satisfies the assertion.
+func Assert(a store.Assertion, body []byte) bool {
+	switch a.Type {
+	case store.AssertContains:
+		return bytes.Contains(body, []byte(a.Value))
+	case store.AssertRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return false
+		}
+
+		return re.Match(body)
+	case store.AssertJSONPath:
+		var data any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return false
+		}
+
+		value, ok := LookupJSONPath(data, a.Path)
+		if !ok {
+			return false
+		}
+
+		return jsonString(value) == a.Value
+	}
+
+	return false
+}
+
+// LookupJSONPath walks a dot separated path like "data.items.0.status".
+func LookupJSONPath(data any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+
+	current := data
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+
+			current = value
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(encoded)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+func TestAssert(t *testing.T) {
+	body := []byte(`{"status":"ok","data":{"items":[{"id":1,"ready":true},{"id":2,"ready":null}]}}`)
+
+	tests := []struct {
+		name      string
+		assertion store.Assertion
+		want      bool
+	}{
+		{"contains", store.Assertion{Type: store.AssertContains, Value: `"status":"ok"`}, true},
+		{"missing text", store.Assertion{Type: store.AssertContains, Value: "error"}, false},
+		{"regex", store.Assertion{Type: store.AssertRegex, Value: `"id":\d+`}, true},
+		{"regex without match", store.Assertion{Type: store.AssertRegex, Value: `"id":"\w+"`}, false},
+		{"invalid regex", store.Assertion{Type: store.AssertRegex, Value: `(`}, false},
+		{"json string", store.Assertion{Type: store.AssertJSONPath, Path: "$.status", Value: "ok"}, true},
+		{"json number", store.Assertion{Type: store.AssertJSONPath, Path: "data.items.0.id", Value: "1"}, true},
+		{"json bool", store.Assertion{Type: store.AssertJSONPath, Path: "data.items.0.ready", Value: "true"}, true},
+		{"json null", store.Assertion{Type: store.AssertJSONPath, Path: "data.items.1.ready", Value: "null"}, true},
+		{"json mismatch", store.Assertion{Type: store.AssertJSONPath, Path: "status", Value: "down"}, false},
+		{"json missing path", store.Assertion{Type: store.AssertJSONPath, Path: "data.items.2.id", Value: "3"}, false},
+		{"unknown type", store.Assertion{Type: "equals", Value: "ok"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Assert(tt.assertion, body); got != tt.want {
+				t.Errorf("Assert(%s) = %v, want %v", tt.assertion, got, tt.want)
+			}
+		})
+	}
+
+	if Assert(store.Assertion{Type: store.AssertJSONPath, Path: "status", Value: "ok"}, []byte("not json")) {
+		t.Error("json path assertion passed on a body that isn't json")
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(`{"a":{"b":[10,{"c":"d"}]},"e":"f"}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"", `{"a":{"b":[10,{"c":"d"}]},"e":"f"}`, true},
+		{"$", `{"a":{"b":[10,{"c":"d"}]},"e":"f"}`, true},
+		{"e", "f", true},
+		{"$.a.b.0", "10", true},
+		{"a.b.1.c", "d", true},
+		{"a.b.2", "", false},
+		{"a.b.-1", "", false},
+		{"a.b.x", "", false},
+		{"e.f", "", false},
+		{"missing", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, ok := LookupJSONPath(data, tt.path)
+			if ok != tt.ok {
+				t.Fatalf("LookupJSONPath(%q) ok = %v, want %v", tt.path, ok, tt.ok)
+			}
+			if ok && jsonString(value) != tt.want {
+				t.Errorf("LookupJSONPath(%q) = %s, want %s", tt.path, jsonString(value), tt.want)
+			}
+		})
+	}
+}
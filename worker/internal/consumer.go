@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/DevanshBhavsar3/echo/common/redisClient"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	MaxDeliveries = int64(3)
+	ReclaimIdle   = time.Minute
+
+	// How often the dns records behind a website are snapshot
+	SnapshotInterval = time.Hour
+)
+
+// Consumer runs the checks of one region from its region stream, and from the
+// legacy shared stream while old publishers are still running. The consumer group
+// is named after the region.
+type Consumer struct {
+	client   redisClient.RedisClient
+	region   store.Region
+	consumer string
+	Streams  []string
+
+	// website id -> time of the last dns snapshot. Messages are handled one at a time.
+	snapshots map[string]time.Time
+}
+
+func NewConsumer(client redisClient.RedisClient, region store.Region, consumer string, legacy bool) *Consumer {
+	streams := []string{redisClient.RegionStream(region.Name)}
+	if legacy {
+		streams = append(streams, redisClient.WebsiteStream)
+	}
+
+	return &Consumer{
+		client:    client,
+		region:    region,
+		consumer:  consumer,
+		Streams:   streams,
+		snapshots: map[string]time.Time{},
+	}
+}
+
+// Handle runs the check of a message and acknowledges it. Messages that fail
+// before a tick is published are left pending to be retried.
+func (c *Consumer) Handle(ctx context.Context, stream string, msg redis.XMessage) {
+	data, _ := msg.Values["data"].(string)
+
+	var payload redisClient.RedisPayload
+	err := json.Unmarshal([]byte(data), &payload)
+	if err != nil {
+		log.Printf("error parsing redis message:\n%v", err)
+		c.deadLetter(ctx, stream, msg, "parse: "+err.Error())
+		return
+	}
+
+	// Messages of other regions on the legacy stream are handled by their own group
+	if payload.RegionName != c.region.Name {
+		c.ack(ctx, stream, msg.ID)
+		return
+	}
+
+	var check store.CheckSpec
+	if len(payload.Check) > 0 {
+		if err := json.Unmarshal(payload.Check, &check); err != nil {
+			log.Printf("error parsing check spec:\n%v", err)
+			c.deadLetter(ctx, stream, msg, "check: "+err.Error())
+			return
+		}
+	}
+
+	runner, ok := RunnerFor(payload.CheckType)
+	if !ok {
+		c.deadLetter(ctx, stream, msg, "check: unknown check type "+payload.CheckType)
+		return
+	}
+
+	// Run the check
+	checkedAt := time.Now()
+	result := runner.Run(ctx, payload.Url, check)
+	result.explain()
+
+	// Snapshot the records behind the website for drift detection
+	if c.snapshotDue(payload.ID, checkedAt) {
+		if records := Snapshot(ctx, payload.Url, snapshotResolver(check), check.Timeout()); records != nil {
+			if result.Details == nil {
+				result.Details = &store.CheckResult{}
+			}
+			result.Details.Records = records
+		}
+	}
+
+	// Ticks are stored at their scheduled time so a redelivered check is deduplicated
+	tickTime := payload.ScheduledAt
+	if tickTime.IsZero() {
+		tickTime = checkedAt
+	}
+
+	tick := store.WebsiteTick{
+		Time:            tickTime,
+		CheckedAt:       &checkedAt,
+		ResponseTimeMS:  &result.ResponseTime,
+		Status:          result.Status.String(),
+		RegionID:        c.region.ID,
+		WebsiteID:       &payload.ID,
+		FailedAssertion: result.FailedAssertion,
+		Result:          result.Details,
+		ContentHash:     result.ContentHash,
+		ContentSnippet:  result.ContentSnippet,
+		Timings:         result.Timings,
+		HTTPStatusCode:  result.StatusCode,
+		ErrorKind:       result.ErrorKind,
+		ErrorMessage:    result.ErrorMessage,
+	}
+
+	encodedTick, err := json.Marshal(tick)
+	if err != nil {
+		log.Printf("error marshaling tick:\n%v", err)
+		return
+	}
+
+	// Add the tick to database stream
+	err = c.client.XAdd(ctx, redisClient.DatabaseStream, encodedTick)
+	if err != nil {
+		return
+	}
+
+	log.Printf("Processed message for region %s", c.region.Name)
+	c.ack(ctx, stream, msg.ID)
+}
+
+// Reclaim claims the messages left pending by crashed workers of the region, dead
+// lettering the ones delivered too many times.
+func (c *Consumer) Reclaim(ctx context.Context) {
+	for _, stream := range c.Streams {
+		msgs := c.client.XAutoClaim(ctx, stream, c.region.Name, c.consumer, ReclaimIdle)
+		if len(msgs) == 0 {
+			continue
+		}
+
+		ids := make([]string, len(msgs))
+		for i, msg := range msgs {
+			ids[i] = msg.ID
+		}
+
+		counts := c.client.DeliveryCounts(ctx, stream, c.region.Name, c.consumer, ids...)
+
+		log.Printf("Reclaimed %d pending messages from %s", len(msgs), stream)
+
+		for _, msg := range msgs {
+			if counts[msg.ID] > MaxDeliveries {
+				c.deadLetter(ctx, stream, msg, "check: exceeded max deliveries")
+				continue
+			}
+
+			c.Handle(ctx, stream, msg)
+		}
+	}
+}
+
+// snapshotDue reports whether the dns records of a website are due for a snapshot
+// and marks them as taken at now.
+func (c *Consumer) snapshotDue(websiteID string, now time.Time) bool {
+	if last, ok := c.snapshots[websiteID]; ok && now.Sub(last) < SnapshotInterval {
+		return false
+	}
+
+	c.snapshots[websiteID] = now
+	return true
+}
+
+// snapshotResolver resolves through the custom resolver of a dns check, and the
+// system resolver otherwise.
+func snapshotResolver(check store.CheckSpec) *net.Resolver {
+	if check.DNS == nil {
+		return net.DefaultResolver
+	}
+
+	return resolver(check.DNS.Resolver, check.Timeout())
+}
+
+func (c *Consumer) ack(ctx context.Context, stream string, id string) {
+	c.client.XAck(ctx, stream, c.region.Name, id)
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, stream string, msg redis.XMessage, reason string) {
+	err := c.client.DeadLetter(ctx, redisClient.WebsiteDeadLetterStream, stream, msg, reason)
+	if err != nil {
+		// Leave it pending so it is retried
+		return
+	}
+
+	c.ack(ctx, stream, msg.ID)
+	log.Printf("Moved message %s to dead letter stream: %s", msg.ID, reason)
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// ContentRunner fetches a page, scopes its body with the selector and regex of the
+// check and hashes it. The check is down when a required keyword is missing or a
+// forbidden one is present.
+type ContentRunner struct{}
+
+func (ContentRunner) Run(ctx context.Context, url string, check store.CheckSpec) Result {
+	spec := store.ContentSpec{}
+	if check.Content != nil {
+		spec = *check.Content
+	}
+
+	client := &http.Client{
+		Timeout:   check.Timeout(),
+		Transport: tracedTransport,
+	}
+
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Result{Status: store.Down}
+	}
+
+	for k, v := range check.Headers {
+		req.Header.Set(k, v)
+	}
+
+	req, timer := traceRequest(req)
+
+	res, err := client.Do(req)
+	if err != nil {
+		result := failed(start, err)
+		result.Timings = timer.Timings(time.Time{})
+		return result
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, store.MaxResponseBody))
+	bodyRead := time.Now()
+	responseTime := bodyRead.Sub(start).Milliseconds()
+	if err != nil {
+		result := Result{Status: store.Down, ResponseTime: responseTime, StatusCode: &res.StatusCode, Timings: timer.Timings(time.Time{})}
+		result.setError(classify(err), err.Error())
+		return result
+	}
+
+	result := Result{
+		Status:       check.StatusFor(res.StatusCode),
+		ResponseTime: responseTime,
+		StatusCode:   &res.StatusCode,
+		Timings:      timer.Timings(bodyRead),
+	}
+
+	content, err := scopeContent(body, spec)
+	if err != nil {
+		result.Status = store.Down
+		result.Details = &store.CheckResult{Error: err.Error()}
+		return result
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	snippet := truncate(strings.Join(strings.Fields(content), " "), store.MaxContentSnippet)
+
+	result.ContentHash = &hash
+	result.ContentSnippet = &snippet
+
+	if result.Status != store.Up {
+		return result
+	}
+
+	for _, keyword := range spec.Required {
+		if !strings.Contains(content, keyword) {
+			failedAssertion := fmt.Sprintf("content required %q", keyword)
+			result.Status = store.Down
+			result.FailedAssertion = &failedAssertion
+			return result
+		}
+	}
+
+	for _, keyword := range spec.Forbidden {
+		if strings.Contains(content, keyword) {
+			failedAssertion := fmt.Sprintf("content forbidden %q", keyword)
+			result.Status = store.Down
+			result.FailedAssertion = &failedAssertion
+			return result
+		}
+	}
+
+	return result
+}
+
+// scopeContent returns the text of the elements matching the selector, or the
+// whole body, narrowed to the matches of the regex.
+func scopeContent(body []byte, spec store.ContentSpec) (string, error) {
+	content := string(body)
+
+	if spec.Selector != "" {
+		selector, err := cascadia.Compile(spec.Selector)
+		if err != nil {
+			return "", err
+		}
+
+		doc, err := html.Parse(bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+
+		var texts []string
+		for _, node := range cascadia.QueryAll(doc, selector) {
+			texts = append(texts, nodeText(node))
+		}
+		content = strings.Join(texts, "\n")
+	}
+
+	if spec.Regex != "" {
+		re, err := regexp.Compile(spec.Regex)
+		if err != nil {
+			return "", err
+		}
+
+		content = strings.Join(re.FindAllString(content, -1), "\n")
+	}
+
+	return content, nil
+}
+
+func nodeText(node *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	return b.String()
+}
+
+// truncate cuts s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
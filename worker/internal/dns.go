@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+var ErrNoAnswers = errors.New("no answers")
+
+// DNSRunner resolves a host, optionally through a custom resolver, and checks the
+// expected answer is among the records returned.
+type DNSRunner struct{}
+
+func (DNSRunner) Run(ctx context.Context, host string, check store.CheckSpec) Result {
+	spec := store.DNSSpec{RecordType: "A"}
+	if check.DNS != nil {
+		spec = *check.DNS
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout())
+	defer cancel()
+
+	start := time.Now()
+
+	answers, err := lookup(ctx, resolver(spec.Resolver, check.Timeout()), spec.RecordType, host)
+	if err == nil && len(answers) == 0 {
+		err = ErrNoAnswers
+	}
+	if err != nil {
+		return failed(start, err)
+	}
+
+	result := Result{
+		Status:       store.Up,
+		ResponseTime: time.Since(start).Milliseconds(),
+		Details: &store.CheckResult{
+			DNS: &store.DNSResult{RecordType: spec.RecordType, Answers: answers},
+		},
+	}
+
+	if spec.Expected != "" && !containsAnswer(answers, spec.Expected) {
+		failedAssertion := fmt.Sprintf("dns %s == %q", spec.RecordType, spec.Expected)
+		result.Status = store.Down
+		result.FailedAssertion = &failedAssertion
+	}
+
+	return result
+}
+
+// resolver returns the system resolver, or one sending every query to address.
+func resolver(address string, timeout time.Duration) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+func lookup(ctx context.Context, r *net.Resolver, recordType string, host string) ([]string, error) {
+	var answers []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+
+		ips, err := r.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		records, err := r.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		records, err := r.LookupNS(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		return r.LookupTXT(ctx, host)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	return answers, nil
+}
+
+// containsAnswer compares names without case or the trailing dot.
+func containsAnswer(answers []string, expected string) bool {
+	expected = strings.TrimSuffix(strings.ToLower(expected), ".")
+
+	for _, answer := range answers {
+		if strings.TrimSuffix(strings.ToLower(answer), ".") == expected {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Snapshot resolves the records of the host of a check target, an url or a
+// host:port, for drift detection. The record types are looked up in parallel
+// through r. Hosts that are ip addresses have no records. Only types the resolver
+// reports as not found are left out, any other failed lookup drops the snapshot so
+// a partial one isn't mistaken for drift.
+func Snapshot(ctx context.Context, target string, r *net.Resolver, timeout time.Duration) store.DNSRecords {
+	host := target
+	if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	} else if h, _, err := net.SplitHostPort(target); err == nil {
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		records = map[string][]string{}
+		failed  bool
+	)
+
+	for _, recordType := range store.SnapshotRecordTypes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			answers, err := lookup(ctx, r, recordType, host)
+			if err != nil {
+				if !isNotFound(err) {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+				return
+			}
+
+			// A host without a CNAME resolves to itself
+			if recordType == "CNAME" && containsAnswer(answers, host) {
+				return
+			}
+
+			mu.Lock()
+			records[recordType] = answers
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if failed || len(records) == 0 {
+		return nil
+	}
+
+	return store.NewDNSRecords(records)
+}
+
+// isNotFound reports whether the resolver answered that the host has no records
+// of the type, rather than failing to get an answer.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
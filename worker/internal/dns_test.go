@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSnapshotDroppedOnFailedLookups(t *testing.T) {
+	unreachable := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("unreachable")
+		},
+	}
+
+	if records := Snapshot(context.Background(), "https://example.com", unreachable, time.Second); records != nil {
+		t.Errorf("snapshot = %v, want none when the resolver can't be reached", records)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not found", &net.DNSError{Err: "no such host", IsNotFound: true}, true},
+		{"wrapped not found", fmt.Errorf("lookup: %w", &net.DNSError{IsNotFound: true}), true},
+		{"timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, false},
+		{"server failure", &net.DNSError{Err: "server misbehaving", IsTemporary: true}, false},
+		{"other", errors.New("unreachable"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotFound(tt.err); got != tt.want {
+				t.Errorf("isNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
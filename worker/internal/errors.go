@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// classify returns the kind of error that stopped a check from reaching its
+// target. DNS timeouts are dns errors so a flaky resolver isn't taken for a slow
+// backend.
+func classify(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return store.ErrorDNSNotFound
+		}
+		return store.ErrorDNS
+	}
+	if errors.Is(err, ErrNoAnswers) {
+		return store.ErrorDNS
+	}
+
+	if isTLSError(err) {
+		return store.ErrorTLS
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return store.ErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return store.ErrorConnectionReset
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return store.ErrorTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return store.ErrorConnection
+	}
+
+	return store.ErrorUnknown
+}
+
+func isTLSError(err error) bool {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordErr       tls.RecordHeaderError
+		alertErr        tls.AlertError
+		authorityErr    x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidErr      x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verificationErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// HTTPRunner requests the url and checks its status code and body assertions.
+// RootCAs are trusted instead of the system roots when set.
+type HTTPRunner struct {
+	RootCAs *x509.CertPool
+}
+
+func (r HTTPRunner) Run(ctx context.Context, url string, check store.CheckSpec) Result {
+	client := &http.Client{
+		Timeout:   check.Timeout(),
+		Transport: transportFor(r.RootCAs),
+	}
+
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, check.HttpMethod(), url, strings.NewReader(check.Body))
+	if err != nil {
+		return Result{Status: store.Down}
+	}
+
+	for k, v := range check.Headers {
+		req.Header.Set(k, v)
+	}
+
+	req, timer := traceRequest(req)
+
+	res, err := client.Do(req)
+	if err != nil {
+		result := failed(start, err)
+		result.Timings = timer.Timings(time.Time{})
+		return result
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, store.MaxResponseBody))
+	bodyRead := time.Now()
+	responseTime := bodyRead.Sub(start).Milliseconds()
+	if err != nil {
+		result := Result{Status: store.Down, ResponseTime: responseTime, StatusCode: &res.StatusCode, Timings: timer.Timings(time.Time{})}
+		result.setError(classify(err), err.Error())
+		return result
+	}
+
+	result := Result{
+		Status:       check.StatusFor(res.StatusCode),
+		ResponseTime: responseTime,
+		StatusCode:   &res.StatusCode,
+		Timings:      timer.Timings(bodyRead),
+	}
+
+	// Keep the chain of https checks to track its expiry
+	if res.TLS != nil {
+		result.Details = &store.CheckResult{Certificates: certificates(res.TLS.PeerCertificates)}
+	}
+
+	if result.Status != store.Up {
+		return result
+	}
+
+	for _, a := range check.Assertions {
+		if !Assert(a, body) {
+			failedAssertion := a.String()
+			result.Status = store.Down
+			result.FailedAssertion = &failedAssertion
+			return result
+		}
+	}
+
+	return result
+}
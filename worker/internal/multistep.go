@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// MultiStepRunner runs the steps of a check in order, carrying the variables
+// extracted from each response into the following steps. The check stops at the
+// first failing step and its response time is the total of the steps.
+type MultiStepRunner struct{}
+
+func (MultiStepRunner) Run(ctx context.Context, baseUrl string, check store.CheckSpec) Result {
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return Result{Status: store.Down, Details: &store.CheckResult{Error: err.Error()}}
+	}
+
+	// One client keeps the cookies of the flow between steps
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Timeout: check.Timeout(),
+		Jar:     jar,
+	}
+
+	vars := map[string]string{}
+	result := Result{
+		Status:  store.Up,
+		Details: &store.CheckResult{Steps: []store.StepResult{}},
+	}
+
+	for _, step := range check.Steps {
+		stepResult := runStep(ctx, client, base, step, check.TimeoutMS, vars)
+
+		result.ResponseTime += stepResult.ResponseTimeMS
+		result.Details.Steps = append(result.Details.Steps, stepResult)
+
+		if stepResult.Status != store.Up.String() {
+			result.Status, _ = store.ParseWebsiteStatus(stepResult.Status)
+			if stepResult.FailedAssertion != "" {
+				failedAssertion := fmt.Sprintf("step %q: %s", step.Name, stepResult.FailedAssertion)
+				result.FailedAssertion = &failedAssertion
+			}
+			if stepResult.StatusCode != 0 {
+				result.StatusCode = &stepResult.StatusCode
+			}
+			if stepResult.ErrorKind != "" {
+				result.setError(stepResult.ErrorKind, fmt.Sprintf("step %q: %s", step.Name, stepResult.Error))
+			}
+			break
+		}
+	}
+
+	return result
+}
+
+func runStep(ctx context.Context, client *http.Client, base *url.URL, step store.Step, timeoutMS int64, vars map[string]string) store.StepResult {
+	result := store.StepResult{
+		Name:   step.Name,
+		Status: store.Down.String(),
+	}
+
+	spec := step.Spec(timeoutMS)
+
+	target, err := base.Parse(interpolate(step.Url, vars))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, spec.HttpMethod(), target.String(), strings.NewReader(interpolate(step.Body, vars)))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for k, v := range step.Headers {
+		req.Header.Set(k, interpolate(v, vars))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		result.ResponseTimeMS = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		result.ErrorKind = classify(err)
+		return result
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, store.MaxResponseBody))
+	result.ResponseTimeMS = time.Since(start).Milliseconds()
+	result.StatusCode = res.StatusCode
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorKind = classify(err)
+		return result
+	}
+
+	status := spec.StatusFor(res.StatusCode)
+	result.Status = status.String()
+	if status != store.Up {
+		return result
+	}
+
+	for _, a := range step.Assertions {
+		if !Assert(a, body) {
+			result.Status = store.Down.String()
+			result.FailedAssertion = a.String()
+			return result
+		}
+	}
+
+	for _, e := range step.Extract {
+		value, ok := extract(e, res.Header, body)
+		if !ok {
+			result.Status = store.Down.String()
+			result.FailedAssertion = fmt.Sprintf("extract %s %s %q", e.Name, e.From, e.Path)
+			return result
+		}
+
+		vars[e.Name] = value
+	}
+
+	return result
+}
+
+// extract reads the value of an extraction from a step response.
+func extract(e store.Extraction, header http.Header, body []byte) (string, bool) {
+	switch e.From {
+	case store.ExtractJSONPath:
+		var data any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return "", false
+		}
+
+		value, ok := LookupJSONPath(data, e.Path)
+		if !ok {
+			return "", false
+		}
+
+		return jsonString(value), true
+	case store.ExtractHeader:
+		value := header.Get(e.Path)
+		return value, value != ""
+	case store.ExtractRegex:
+		re, err := regexp.Compile(e.Path)
+		if err != nil {
+			return "", false
+		}
+
+		match := re.FindSubmatch(body)
+		switch {
+		case match == nil:
+			return "", false
+		case len(match) > 1:
+			return string(match[1]), true
+		default:
+			return string(match[0]), true
+		}
+	}
+
+	return "", false
+}
+
+// interpolate replaces {{name}} with the value of the variable. Unknown variables
+// are left as they are.
+func interpolate(s string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(s, "{{") {
+		return s
+	}
+
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{{"+name+"}}", value)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(s)
+}
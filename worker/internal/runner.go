@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status          store.WebsiteStatus
+	ResponseTime    int64
+	FailedAssertion *string
+	Details         *store.CheckResult
+	ContentHash     *string
+	ContentSnippet  *string
+	Timings         store.Timings
+	StatusCode      *int
+	ErrorKind       *string
+	ErrorMessage    *string
+}
+
+// CheckRunner runs one type of check against a target, which is an url for
+// http checks and a host or host:port for the others.
+type CheckRunner interface {
+	Run(ctx context.Context, target string, check store.CheckSpec) Result
+}
+
+var Runners = map[store.CheckType]CheckRunner{
+	store.CheckHTTP:      HTTPRunner{},
+	store.CheckTCP:       TCPRunner{},
+	store.CheckDNS:       DNSRunner{},
+	store.CheckTLS:       TLSRunner{},
+	store.CheckContent:   ContentRunner{},
+	store.CheckMultiStep: MultiStepRunner{},
+}
+
+// RunnerFor returns the runner of a check type. Payloads published before check
+// types existed have no type and are http checks.
+func RunnerFor(checkType string) (CheckRunner, bool) {
+	if checkType == "" {
+		checkType = string(store.CheckHTTP)
+	}
+
+	runner, ok := Runners[store.CheckType(checkType)]
+	return runner, ok
+}
+
+// failed is the result of a check that could not reach its target.
+func failed(start time.Time, err error) Result {
+	result := Result{
+		Status:       store.Down,
+		ResponseTime: time.Since(start).Milliseconds(),
+		Details:      &store.CheckResult{Error: err.Error()},
+	}
+	result.setError(classify(err), err.Error())
+
+	return result
+}
+
+func (r *Result) setError(kind string, message string) {
+	message = truncate(message, store.MaxErrorMessage)
+
+	r.ErrorKind = &kind
+	r.ErrorMessage = &message
+}
+
+// explain records why a down check failed when its runner didn't.
+func (r *Result) explain() {
+	if r.Status != store.Down || r.ErrorKind != nil {
+		return
+	}
+
+	switch {
+	case r.FailedAssertion != nil:
+		r.setError(store.ErrorAssertion, *r.FailedAssertion)
+	case r.StatusCode != nil:
+		r.setError(store.ErrorHTTPStatus, fmt.Sprintf("%d %s", *r.StatusCode, http.StatusText(*r.StatusCode)))
+	case r.Details != nil && r.Details.Error != "":
+		r.setError(store.ErrorUnknown, r.Details.Error)
+	default:
+		r.setError(store.ErrorUnknown, "check failed")
+	}
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// TCPRunner opens a tcp connection to a host:port and closes it.
+type TCPRunner struct{}
+
+func (TCPRunner) Run(ctx context.Context, address string, check store.CheckSpec) Result {
+	dialer := net.Dialer{Timeout: check.Timeout()}
+
+	start := time.Now()
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return failed(start, err)
+	}
+	responseTime := time.Since(start).Milliseconds()
+	conn.Close()
+
+	return Result{
+		Status:       store.Up,
+		ResponseTime: responseTime,
+		Details: &store.CheckResult{
+			TCP: &store.TCPResult{RemoteAddr: conn.RemoteAddr().String()},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// DefaultTLSPort is used when the target of a tls check has no port.
+var DefaultTLSPort = "443"
+
+// TLSRunner completes a tls handshake with a host, verifying its certificate
+// chain, without sending a request. RootCAs are trusted instead of the system
+// roots when set.
+type TLSRunner struct {
+	RootCAs *x509.CertPool
+}
+
+func (r TLSRunner) Run(ctx context.Context, address string, check store.CheckSpec) Result {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+		address = net.JoinHostPort(address, DefaultTLSPort)
+	}
+
+	serverName := host
+	if check.TLS != nil && check.TLS.ServerName != "" {
+		serverName = check.TLS.ServerName
+	}
+
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: check.Timeout()},
+		Config:    &tls.Config{ServerName: serverName, RootCAs: r.RootCAs},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout())
+	defer cancel()
+
+	start := time.Now()
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return failed(start, err)
+	}
+	responseTime := time.Since(start).Milliseconds()
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+
+	return Result{
+		Status:       store.Up,
+		ResponseTime: responseTime,
+		Details: &store.CheckResult{
+			TLS: &store.TLSResult{
+				Version: tls.VersionName(state.Version),
+				Cipher:  tls.CipherSuiteName(state.CipherSuite),
+			},
+			Certificates: certificates(state.PeerCertificates),
+		},
+	}
+}
+
+// certificates describes a peer certificate chain, leaf first.
+func certificates(chain []*x509.Certificate) []store.Certificate {
+	var certs []store.Certificate
+
+	for _, c := range chain {
+		certs = append(certs, store.Certificate{
+			Subject:   c.Subject.String(),
+			Issuer:    c.Issuer.String(),
+			DNSNames:  c.DNSNames,
+			NotBefore: c.NotBefore,
+			NotAfter:  c.NotAfter,
+		})
+	}
+
+	return certs
+}
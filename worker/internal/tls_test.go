@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// shortLivedServer starts a tls server with a self signed certificate valid from
+// notBefore to notAfter, and returns it with a pool trusting the certificate.
+func shortLivedServer(t *testing.T, notBefore, notAfter time.Time) (*httptest.Server, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "echo test"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	// Failed handshakes are expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server, roots
+}
+
+func TestHTTPRunnerRecordsShortLivedCertificate(t *testing.T) {
+	now := time.Now()
+	notAfter := now.Add(36 * time.Hour).Truncate(time.Second)
+	server, roots := shortLivedServer(t, now.Add(-time.Hour), notAfter)
+
+	result := HTTPRunner{RootCAs: roots}.Run(context.Background(), server.URL, store.CheckSpec{})
+
+	if result.Status != store.Up {
+		t.Fatalf("status = %s, want up", result.Status)
+	}
+	if result.Details == nil || len(result.Details.Certificates) != 1 {
+		t.Fatalf("certificates = %+v, want the leaf", result.Details)
+	}
+
+	leaf := result.Details.Certificates[0]
+	if !leaf.NotAfter.Equal(notAfter) {
+		t.Errorf("not after = %s, want %s", leaf.NotAfter, notAfter)
+	}
+	if leaf.Subject != "CN=echo test" {
+		t.Errorf("subject = %q, want CN=echo test", leaf.Subject)
+	}
+
+	certificate := store.WebsiteCertificate{Certificate: leaf}
+	if days := certificate.DaysUntilExpiry(now); days != 1 {
+		t.Errorf("days until expiry = %d, want 1", days)
+	}
+	if state := certificate.State(store.DefaultCertificateExpiryDays, now); state != store.CertificateWarning {
+		t.Errorf("state = %s, want %s", state, store.CertificateWarning)
+	}
+	if state := certificate.State(store.DefaultCertificateExpiryDays, notAfter); state != store.CertificateExpired {
+		t.Errorf("state at expiry = %s, want %s", state, store.CertificateExpired)
+	}
+}
+
+func TestTLSRunnerRecordsShortLivedCertificate(t *testing.T) {
+	now := time.Now()
+	server, roots := shortLivedServer(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	result := TLSRunner{RootCAs: roots}.Run(context.Background(), server.Listener.Addr().String(), store.CheckSpec{})
+
+	if result.Status != store.Up {
+		t.Fatalf("status = %s, want up: %+v", result.Status, result.Details)
+	}
+	if result.Details.TLS == nil || result.Details.TLS.Version == "" {
+		t.Errorf("tls = %+v, want the negotiated version", result.Details.TLS)
+	}
+	if len(result.Details.Certificates) != 1 {
+		t.Errorf("certificates = %d, want 1", len(result.Details.Certificates))
+	}
+}
+
+func TestTLSRunnerFailsOnExpiredCertificate(t *testing.T) {
+	now := time.Now()
+	server, roots := shortLivedServer(t, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	result := TLSRunner{RootCAs: roots}.Run(context.Background(), server.Listener.Addr().String(), store.CheckSpec{})
+
+	if result.Status != store.Down {
+		t.Fatalf("status = %s, want down", result.Status)
+	}
+	if result.ErrorKind == nil || *result.ErrorKind != store.ErrorTLS {
+		t.Errorf("error kind = %v, want %s", result.ErrorKind, store.ErrorTLS)
+	}
+}
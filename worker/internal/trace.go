@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/DevanshBhavsar3/echo/common/db/store"
+)
+
+// tracedTransport dials a new connection for every request, so each check times
+// the full DNS lookup, connect and TLS handshake instead of reusing a kept-alive
+// connection from an earlier check to the same host.
+var tracedTransport = func() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	return transport
+}()
+
+// transportFor returns tracedTransport, trusting roots instead of the system roots
+// when set.
+func transportFor(roots *x509.CertPool) *http.Transport {
+	if roots == nil {
+		return tracedTransport
+	}
+
+	transport := tracedTransport.Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	return transport
+}
+
+// phaseTimer records when the phases of a request start and end. Dialing can run
+// in other goroutines, so every access is locked.
+type phaseTimer struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+}
+
+// traceRequest returns the request with a trace recording its phases.
+func traceRequest(req *http.Request) (*http.Request, *phaseTimer) {
+	t := &phaseTimer{}
+
+	record := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		// Only the first attempt counts when several addresses are dialed
+		if at.IsZero() {
+			*at = time.Now()
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// Timings returns the phases that completed, the transfer ending at bodyRead.
+func (t *phaseTimer) Timings(bodyRead time.Time) store.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return store.Timings{
+		DNSMS:      phase(t.dnsStart, t.dnsDone),
+		ConnectMS:  phase(t.connectStart, t.connectDone),
+		TLSMS:      phase(t.tlsStart, t.tlsDone),
+		TTFBMS:     phase(t.wroteRequest, t.firstByte),
+		TransferMS: phase(t.firstByte, bodyRead),
+	}
+}
+
+func phase(start, end time.Time) *int64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return nil
+	}
+
+	ms := end.Sub(start).Milliseconds()
+	return &ms
+}